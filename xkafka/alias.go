@@ -4,3 +4,6 @@ import "github.com/confluentinc/confluent-kafka-go/v2/kafka"
 
 // Metadata contains broker and topic metadata for all (matching) topics
 type Metadata = kafka.Metadata
+
+// ErrorCode is the error code of a Kafka error.
+type ErrorCode = kafka.ErrorCode
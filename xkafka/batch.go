@@ -7,11 +7,32 @@ import (
 	"github.com/rs/xid"
 )
 
+// FlushReason is an enum for the reason a Batch was sent to the handler.
+type FlushReason int
+
+// FlushReason enums.
+const (
+	FlushUnknown FlushReason = iota
+	// FlushSize is set when the batch reached the BatchSize.
+	FlushSize
+	// FlushTimeout is set when the BatchTimeout expired before the
+	// batch was full.
+	FlushTimeout
+	// FlushShutdown is set for the last batch, when the consumer stops.
+	FlushShutdown
+)
+
+// String returns a string flush reason value.
+func (r FlushReason) String() string {
+	return [...]string{"UNKNOWN", "SIZE", "TIMEOUT", "SHUTDOWN"}[r]
+}
+
 // Batch is a group of messages that are processed together.
 type Batch struct {
-	ID       string
-	Messages []*Message
-	Status   Status
+	ID          string
+	Messages    []*Message
+	Status      Status
+	FlushReason FlushReason
 
 	err  error
 	lock sync.Mutex
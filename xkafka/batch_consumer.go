@@ -90,7 +90,11 @@ func (c *BatchConsumer) start(ctx context.Context) error {
 }
 
 func (c *BatchConsumer) runSequential(ctx context.Context) (err error) {
+	p := newPoller(c.kafka, c.config)
+
 	defer func() {
+		p.stop()
+
 		if uerr := c.unsubscribe(); uerr != nil {
 			err = errors.Join(err, uerr)
 		}
@@ -101,18 +105,16 @@ func (c *BatchConsumer) runSequential(ctx context.Context) (err error) {
 
 	defer timer.Stop()
 
+	events := p.poll()
+
 	for {
 		select {
 		case <-ctx.Done():
-			if err := c.processBatch(ctx, batch); err != nil {
-				return err
-			}
-
-			return nil
+			return c.processBatch(ctx, batch, FlushShutdown)
 
 		case <-timer.C:
 			if len(batch.Messages) > 0 {
-				if err := c.processBatch(ctx, batch); err != nil {
+				if err := c.processBatch(ctx, batch, FlushTimeout); err != nil {
 					return err
 				}
 
@@ -121,26 +123,20 @@ func (c *BatchConsumer) runSequential(ctx context.Context) (err error) {
 
 			timer.Reset(c.config.batchTimeout)
 
-		default:
-			km, err := c.kafka.ReadMessage(c.config.pollTimeout)
+		case e := <-events:
+			km, err := c.config.handleEvent(ctx, c.kafka, e)
 			if err != nil {
-				var kerr kafka.Error
-				if errors.As(err, &kerr) && kerr.Code() == kafka.ErrTimedOut {
-					continue
-				}
-
-				if ferr := c.config.errorHandler(err); ferr != nil {
+				if ferr := c.config.errorHandler(classifyError(err)); ferr != nil {
 					return ferr
 				}
-
-				continue
 			}
 
-			msg := newMessage(c.name, km)
-			batch.Messages = append(batch.Messages, msg)
+			if km != nil {
+				batch.Messages = append(batch.Messages, newMessage(c.name, km))
+			}
 
 			if len(batch.Messages) >= c.config.batchSize {
-				if err := c.processBatch(ctx, batch); err != nil {
+				if err := c.processBatch(ctx, batch, FlushSize); err != nil {
 					return err
 				}
 
@@ -148,6 +144,8 @@ func (c *BatchConsumer) runSequential(ctx context.Context) (err error) {
 
 				timer.Reset(c.config.batchTimeout)
 			}
+
+			events = p.poll()
 		}
 	}
 }
@@ -158,70 +156,81 @@ func (c *BatchConsumer) runAsync(ctx context.Context) error {
 
 	defer cancel(nil)
 
+	var failure firstError
+
+	fail := func(err error) {
+		failure.set(err)
+		cancel(err)
+	}
+
 	batch := NewBatch()
 	timer := time.NewTimer(c.config.batchTimeout)
 
 	defer timer.Stop()
 
+	p := newPoller(c.kafka, c.config)
+	events := p.poll()
+
 	for {
 		select {
 		case <-ctx.Done():
+			p.stop()
 			st.Wait()
 
-			err := c.processBatch(ctx, batch)
+			err := c.processBatch(ctx, batch, FlushShutdown)
 			uerr := c.unsubscribe()
 			err = errors.Join(err, uerr)
 
-			cerr := context.Cause(ctx)
-			if cerr != nil && !errors.Is(cerr, context.Canceled) {
-				err = errors.Join(err, cerr)
+			ferr := failure.get()
+			if cerr := context.Cause(ctx); ferr == nil && !errors.Is(cerr, context.Canceled) {
+				ferr = cerr
 			}
 
-			return err
+			return errors.Join(err, ferr)
 
 		case <-timer.C:
 			if len(batch.Messages) > 0 {
-				c.processBatchAsync(ctx, batch, st, cancel)
+				c.processBatchAsync(ctx, batch, FlushTimeout, st, fail)
 				batch = NewBatch()
 			}
 
 			timer.Reset(c.config.batchTimeout)
 
-		default:
-			km, err := c.kafka.ReadMessage(c.config.pollTimeout)
+		case e := <-events:
+			km, err := c.config.handleEvent(ctx, c.kafka, e)
 			if err != nil {
-				var kerr kafka.Error
-				if errors.As(err, &kerr) && kerr.Code() == kafka.ErrTimedOut {
-					continue
-				}
+				if ferr := c.config.errorHandler(classifyError(err)); ferr != nil {
+					fail(ferr)
 
-				if ferr := c.config.errorHandler(err); ferr != nil {
-					cancel(ferr)
+					continue
 				}
-
-				continue
 			}
 
-			msg := newMessage(c.name, km)
-			batch.Messages = append(batch.Messages, msg)
+			if km != nil {
+				batch.Messages = append(batch.Messages, newMessage(c.name, km))
+			}
 
 			if len(batch.Messages) >= c.config.batchSize {
-				c.processBatchAsync(ctx, batch, st, cancel)
+				c.processBatchAsync(ctx, batch, FlushSize, st, fail)
 				batch = NewBatch()
 
 				timer.Reset(c.config.batchTimeout)
 			}
+
+			events = p.poll()
 		}
 	}
 }
 
-func (c *BatchConsumer) processBatch(ctx context.Context, batch *Batch) error {
+func (c *BatchConsumer) processBatch(ctx context.Context, batch *Batch, reason FlushReason) error {
 	if len(batch.Messages) == 0 {
 		return nil
 	}
 
+	batch.FlushReason = reason
+
 	err := c.handler.HandleBatch(ctx, batch)
-	if ferr := c.config.errorHandler(err); ferr != nil {
+	if ferr := c.config.errorHandler(handlerError(err)); ferr != nil {
 		return ferr
 	}
 
@@ -231,13 +240,16 @@ func (c *BatchConsumer) processBatch(ctx context.Context, batch *Batch) error {
 func (c *BatchConsumer) processBatchAsync(
 	ctx context.Context,
 	batch *Batch,
+	reason FlushReason,
 	st *stream.Stream,
-	cancel context.CancelCauseFunc,
+	fail func(err error),
 ) {
+	batch.FlushReason = reason
+
 	st.Go(func() stream.Callback {
 		err := c.handler.HandleBatch(ctx, batch)
-		if ferr := c.config.errorHandler(err); ferr != nil {
-			cancel(ferr)
+		if ferr := c.config.errorHandler(handlerError(err)); ferr != nil {
+			fail(ferr)
 
 			return func() {
 				c.stopOffset.Store(true)
@@ -246,7 +258,7 @@ func (c *BatchConsumer) processBatchAsync(
 
 		return func() {
 			if err := c.storeBatch(batch); err != nil {
-				cancel(err)
+				fail(err)
 			}
 		}
 	})
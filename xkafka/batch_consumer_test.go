@@ -124,7 +124,7 @@ func TestBatchConsumer_Lifecycle(t *testing.T) {
 		consumer, mockKafka := newTestBatchConsumer(t, defaultOpts...)
 
 		mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
-		mockKafka.On("ReadMessage", testTimeout).Return(newFakeKafkaMessage(), nil)
+		mockKafka.On("Poll", testPollTimeout).Return(newFakeKafkaMessage())
 		mockKafka.On("Commit").Return(nil, nil)
 		mockKafka.On("Close").Return(nil)
 
@@ -147,7 +147,7 @@ func TestBatchConsumer_Lifecycle(t *testing.T) {
 
 		mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 		mockKafka.On("Unsubscribe").Return(nil)
-		mockKafka.On("ReadMessage", testTimeout).Return(newFakeKafkaMessage(), nil)
+		mockKafka.On("Poll", testPollTimeout).Return(newFakeKafkaMessage())
 		mockKafka.On("Commit").Return(nil, nil)
 		mockKafka.On("Close").Return(errors.New("error in close"))
 
@@ -168,7 +168,7 @@ func TestBatchConsumer_Lifecycle(t *testing.T) {
 
 		mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 		mockKafka.On("Unsubscribe").Return(nil)
-		mockKafka.On("ReadMessage", testTimeout).Return(newFakeKafkaMessage(), nil)
+		mockKafka.On("Poll", testPollTimeout).Return(newFakeKafkaMessage())
 		mockKafka.On("Commit").Return(nil, nil)
 		mockKafka.On("Close").Return(nil)
 
@@ -197,6 +197,7 @@ func TestBatchConsumer_HandleBatch(t *testing.T) {
 	handler := BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
 		assert.NotNil(t, b)
 		assert.Len(t, b.Messages, 10)
+		assert.Equal(t, FlushSize, b.FlushReason)
 
 		cancel()
 		return nil
@@ -205,7 +206,7 @@ func TestBatchConsumer_HandleBatch(t *testing.T) {
 	mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 	mockKafka.On("Unsubscribe").Return(nil)
 	mockKafka.On("Commit").Return(nil, nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Close").Return(nil)
 
 	consumer.handler = handler
@@ -255,7 +256,7 @@ func TestBatchConsumer_HandleBatchError(t *testing.T) {
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 			mockKafka.On("Close").Return(nil)
 
 			consumer.handler = handler
@@ -304,7 +305,7 @@ func TestBatchConsumer_Async(t *testing.T) {
 	mockKafka.On("Unsubscribe").Return(nil)
 	mockKafka.On("Commit").Return(nil, nil)
 	mockKafka.On("StoreOffsets", mock.Anything).Return(nil, nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Close").Return(nil)
 
 	consumer.handler = handler
@@ -370,7 +371,7 @@ func TestBatchConsumer_StopOffsetOnError(t *testing.T) {
 			}).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 			mockKafka.On("Close").Return(nil)
 
 			mockKafka.On("StoreOffsets", mock.Anything).
@@ -421,6 +422,7 @@ func TestBatchConsumer_BatchTimeout(t *testing.T) {
 
 				assert.NotNil(t, b)
 				assert.True(t, len(b.Messages) > 0)
+				assert.Contains(t, []FlushReason{FlushTimeout, FlushShutdown}, b.FlushReason)
 
 				return nil
 			})
@@ -434,7 +436,7 @@ func TestBatchConsumer_BatchTimeout(t *testing.T) {
 			mockKafka.On("Unsubscribe").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
 			mockKafka.On("StoreOffsets", mock.Anything).Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 			mockKafka.On("Close").Return(nil)
 
 			consumer.handler = handler
@@ -463,7 +465,7 @@ func TestBatchConsumer_MiddlewareExecutionOrder(t *testing.T) {
 	mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 	mockKafka.On("Unsubscribe").Return(nil)
 	mockKafka.On("Commit").Return(nil, nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Close").Return(nil)
 
 	handler := BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
@@ -497,7 +499,7 @@ func TestBatchConsumer_MiddlewareExecutionOrder(t *testing.T) {
 	mockKafka.AssertExpectations(t)
 }
 
-func TestBatchConsumer_ReadMessageTimeout(t *testing.T) {
+func TestBatchConsumer_PollTimeout(t *testing.T) {
 	t.Parallel()
 
 	testcases := []struct {
@@ -542,9 +544,9 @@ func TestBatchConsumer_ReadMessageTimeout(t *testing.T) {
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil).Once()
-			mockKafka.On("ReadMessage", testTimeout).Return(nil, expect).Once()
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km).Once()
+			mockKafka.On("Poll", testPollTimeout).Return(expect).Once()
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 			mockKafka.On("Close").Return(nil)
 
 			consumer.handler = handler
@@ -592,11 +594,11 @@ func TestBatchConsumer_KafkaError(t *testing.T) {
 			mockKafka.On("Commit").Return(nil, nil)
 			mockKafka.On("Close").Return(nil)
 
-			mockKafka.On("ReadMessage", testTimeout).
-				Return(km, nil).
+			mockKafka.On("Poll", testPollTimeout).
+				Return(km).
 				Times(3)
-			mockKafka.On("ReadMessage", testTimeout).
-				Return(nil, expect).
+			mockKafka.On("Poll", testPollTimeout).
+				Return(expect).
 				Once()
 
 			err := consumer.Run(ctx)
@@ -647,7 +649,7 @@ func TestBatchConsumer_CommitError(t *testing.T) {
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
 			mockKafka.On("StoreOffsets", mock.Anything).Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 			mockKafka.On("Close").Return(nil)
 
 			mockKafka.On("Commit").Return(nil, expect)
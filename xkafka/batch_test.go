@@ -14,6 +14,26 @@ func TestNewBatch(t *testing.T) {
 	assert.NotEmpty(t, batch.ID)
 	assert.Empty(t, batch.Messages)
 	assert.Zero(t, batch.Status)
+	assert.Equal(t, FlushUnknown, batch.FlushReason)
+}
+
+func TestFlushReason_String(t *testing.T) {
+	tests := []struct {
+		name string
+		r    FlushReason
+		want string
+	}{
+		{name: "Unknown", r: FlushUnknown, want: "UNKNOWN"},
+		{name: "Size", r: FlushSize, want: "SIZE"},
+		{name: "Timeout", r: FlushTimeout, want: "TIMEOUT"},
+		{name: "Shutdown", r: FlushShutdown, want: "SHUTDOWN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.r.String(), "String()")
+		})
+	}
 }
 
 func TestBatch_AckSuccess(t *testing.T) {
@@ -1,20 +1,20 @@
 package xkafka
 
-import (
-	"time"
-
-	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
-)
+import "github.com/confluentinc/confluent-kafka-go/v2/kafka"
 
 type consumerClient interface {
 	GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error)
-	ReadMessage(timeout time.Duration) (*kafka.Message, error)
+	Poll(timeoutMs int) kafka.Event
 	SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error
 	Unsubscribe() error
 	Assign(partitions []kafka.TopicPartition) error
 	Unassign() error
 	StoreOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error)
 	Commit() ([]kafka.TopicPartition, error)
+	Committed(partitions []kafka.TopicPartition, timeoutMs int) ([]kafka.TopicPartition, error)
+	QueryWatermarkOffsets(topic string, partition int32, timeoutMs int) (int64, int64, error)
+	SetOAuthBearerToken(oauthBearerToken kafka.OAuthBearerToken) error
+	SetOAuthBearerTokenFailure(errstr string) error
 	Close() error
 }
 
@@ -31,6 +31,9 @@ type producerClient interface {
 	ProduceChannel() chan *kafka.Message
 	Events() chan kafka.Event
 	Flush(timeoutMs int) int
+	Len() int
+	SetOAuthBearerToken(oauthBearerToken kafka.OAuthBearerToken) error
+	SetOAuthBearerTokenFailure(errstr string) error
 	Close()
 }
 
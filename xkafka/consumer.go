@@ -75,16 +75,18 @@ func (c *Consumer) Use(mwf ...MiddlewareFunc) {
 // Run starts running the Consumer. The component will stop running
 // when the context is closed. Run blocks until the context is closed or
 // an error occurs.
-func (c *Consumer) Run(ctx context.Context) error {
+func (c *Consumer) Run(ctx context.Context) (err error) {
 	if err := c.subscribe(); err != nil {
 		return err
 	}
 
-	if err := c.start(ctx); err != nil {
-		return err
-	}
+	defer func() {
+		if cerr := c.close(); cerr != nil {
+			err = errors.Join(err, cerr)
+		}
+	}()
 
-	return c.close()
+	return c.start(ctx)
 }
 
 // Start subscribes to the configured topics and starts consuming messages.
@@ -129,104 +131,112 @@ func (c *Consumer) start(ctx context.Context) error {
 }
 
 func (c *Consumer) runSequential(ctx context.Context) (err error) {
+	p := newPoller(c.kafka, c.config)
+
 	defer func() {
+		p.stop()
+
 		if uerr := c.unsubscribe(); uerr != nil {
 			err = errors.Join(err, uerr)
 		}
 	}()
 
+	events := p.poll()
+
 	for {
 		select {
 		case <-ctx.Done():
-			return err
-		default:
-			km, err := c.kafka.ReadMessage(c.config.pollTimeout)
+			return nil
+		case e := <-events:
+			km, err := c.config.handleEvent(ctx, c.kafka, e)
 			if err != nil {
-				var kerr kafka.Error
-				if errors.As(err, &kerr) && kerr.Code() == kafka.ErrTimedOut {
-					continue
+				if ferr := c.config.errorHandler(classifyError(err)); ferr != nil {
+					return ferr
 				}
+			}
 
-				if ferr := c.config.errorHandler(err); ferr != nil {
-					err = ferr
-
+			if km != nil {
+				if err := c.process(ctx, newMessage(c.name, km)); err != nil {
 					return err
 				}
-
-				continue
-			}
-
-			msg := newMessage(c.name, km)
-
-			err = c.handler.Handle(ctx, msg)
-			if ferr := c.config.errorHandler(err); ferr != nil {
-				err = ferr
-
-				return err
 			}
 
-			err = c.storeMessage(msg)
-			if err != nil {
-				return err
-			}
+			events = p.poll()
 		}
 	}
 }
 
+func (c *Consumer) process(ctx context.Context, msg *Message) error {
+	err := c.handler.Handle(ctx, msg)
+	if ferr := c.config.errorHandler(handlerError(err)); ferr != nil {
+		return ferr
+	}
+
+	return c.storeMessage(msg)
+}
+
 func (c *Consumer) runAsync(ctx context.Context) error {
 	st := stream.New().WithMaxGoroutines(c.config.concurrency)
 	ctx, cancel := context.WithCancelCause(ctx)
 
+	defer cancel(nil)
+
+	var failure firstError
+
+	fail := func(err error) {
+		failure.set(err)
+		cancel(err)
+	}
+
+	p := newPoller(c.kafka, c.config)
+	events := p.poll()
+
 	for {
 		select {
 		case <-ctx.Done():
+			p.stop()
 			st.Wait()
 
-			defer cancel(nil)
-
 			uerr := c.unsubscribe()
 
-			err := context.Cause(ctx)
-			if err != nil && errors.Is(err, context.Canceled) {
-				return uerr
+			err := failure.get()
+			if cerr := context.Cause(ctx); err == nil && !errors.Is(cerr, context.Canceled) {
+				err = cerr
 			}
 
 			return errors.Join(err, uerr)
-		default:
-			km, err := c.kafka.ReadMessage(c.config.pollTimeout)
+		case e := <-events:
+			km, err := c.config.handleEvent(ctx, c.kafka, e)
 			if err != nil {
-				var kerr kafka.Error
-				if errors.As(err, &kerr) && kerr.Code() == kafka.ErrTimedOut {
-					continue
-				}
-
-				if ferr := c.config.errorHandler(err); ferr != nil {
-					cancel(ferr)
+				if ferr := c.config.errorHandler(classifyError(err)); ferr != nil {
+					fail(ferr)
 
 					continue
 				}
-
-				continue
 			}
 
-			msg := newMessage(c.name, km)
+			if km != nil {
+				msg := newMessage(c.name, km)
 
-			st.Go(func() stream.Callback {
-				err := c.handler.Handle(ctx, msg)
-				if ferr := c.config.errorHandler(err); ferr != nil {
-					cancel(ferr)
+				st.Go(func() stream.Callback {
+					err := c.handler.Handle(ctx, msg)
+					if ferr := c.config.errorHandler(handlerError(err)); ferr != nil {
+						fail(ferr)
 
-					return func() {
-						c.stopOffset.Store(true)
+						return func() {
+							c.stopOffset.Store(true)
+						}
 					}
-				}
 
-				return func() {
-					if err := c.storeMessage(msg); err != nil {
-						cancel(err)
+					return func() {
+						if err := c.storeMessage(msg); err != nil {
+							fail(err)
+						}
 					}
-				}
-			})
+				})
+			}
+
+			events = p.poll()
 		}
 	}
 }
@@ -3,8 +3,6 @@
 package xkafka
 
 import (
-	time "time"
-
 	kafka "github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -54,6 +52,32 @@ func (_m *MockConsumerClient) Commit() ([]kafka.TopicPartition, error) {
 	return r0, r1
 }
 
+// Committed provides a mock function with given fields: partitions, timeoutMs
+func (_m *MockConsumerClient) Committed(partitions []kafka.TopicPartition, timeoutMs int) ([]kafka.TopicPartition, error) {
+	ret := _m.Called(partitions, timeoutMs)
+
+	var r0 []kafka.TopicPartition
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]kafka.TopicPartition, int) ([]kafka.TopicPartition, error)); ok {
+		return rf(partitions, timeoutMs)
+	}
+	if rf, ok := ret.Get(0).(func([]kafka.TopicPartition, int) []kafka.TopicPartition); ok {
+		r0 = rf(partitions, timeoutMs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]kafka.TopicPartition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]kafka.TopicPartition, int) error); ok {
+		r1 = rf(partitions, timeoutMs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetadata provides a mock function with given fields: topic, allTopics, timeoutMs
 func (_m *MockConsumerClient) GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error) {
 	ret := _m.Called(topic, allTopics, timeoutMs)
@@ -80,30 +104,79 @@ func (_m *MockConsumerClient) GetMetadata(topic *string, allTopics bool, timeout
 	return r0, r1
 }
 
-// ReadMessage provides a mock function with given fields: timeout
-func (_m *MockConsumerClient) ReadMessage(timeout time.Duration) (*kafka.Message, error) {
-	ret := _m.Called(timeout)
+// Poll provides a mock function with given fields: timeoutMs
+func (_m *MockConsumerClient) Poll(timeoutMs int) kafka.Event {
+	ret := _m.Called(timeoutMs)
 
-	var r0 *kafka.Message
-	var r1 error
-	if rf, ok := ret.Get(0).(func(time.Duration) (*kafka.Message, error)); ok {
-		return rf(timeout)
-	}
-	if rf, ok := ret.Get(0).(func(time.Duration) *kafka.Message); ok {
-		r0 = rf(timeout)
+	var r0 kafka.Event
+	if rf, ok := ret.Get(0).(func(int) kafka.Event); ok {
+		r0 = rf(timeoutMs)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*kafka.Message)
+			r0 = ret.Get(0).(kafka.Event)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
-		r1 = rf(timeout)
+	return r0
+}
+
+// QueryWatermarkOffsets provides a mock function with given fields: topic, partition, timeoutMs
+func (_m *MockConsumerClient) QueryWatermarkOffsets(topic string, partition int32, timeoutMs int) (int64, int64, error) {
+	ret := _m.Called(topic, partition, timeoutMs)
+
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, int32, int) (int64, int64, error)); ok {
+		return rf(topic, partition, timeoutMs)
+	}
+	if rf, ok := ret.Get(0).(func(string, int32, int) int64); ok {
+		r0 = rf(topic, partition, timeoutMs)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(1).(func(string, int32, int) int64); ok {
+		r1 = rf(topic, partition, timeoutMs)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, int32, int) error); ok {
+		r2 = rf(topic, partition, timeoutMs)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetOAuthBearerToken provides a mock function with given fields: oauthBearerToken
+func (_m *MockConsumerClient) SetOAuthBearerToken(oauthBearerToken kafka.OAuthBearerToken) error {
+	ret := _m.Called(oauthBearerToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(kafka.OAuthBearerToken) error); ok {
+		r0 = rf(oauthBearerToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetOAuthBearerTokenFailure provides a mock function with given fields: errstr
+func (_m *MockConsumerClient) SetOAuthBearerTokenFailure(errstr string) error {
+	ret := _m.Called(errstr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(errstr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // StoreOffsets provides a mock function with given fields: offsets
@@ -22,6 +22,12 @@ type consumerConfig struct {
 	concurrency     int
 	manualCommit    bool
 
+	securityConfig
+
+	// event callbacks
+	statsCb        StatsCallback
+	partitionEOFCb PartitionEOFCallback
+
 	// batch options
 	batchSize    int
 	batchTimeout time.Duration
@@ -34,7 +40,7 @@ func newConsumerConfig(opts ...ConsumerOption) (*consumerConfig, error) {
 		brokers:         []string{},
 		configMap:       kafka.ConfigMap{},
 		metadataTimeout: 10 * time.Second,
-		pollTimeout:     10 * time.Second,
+		pollTimeout:     100 * time.Millisecond,
 		shutdownTimeout: 1 * time.Second,
 		concurrency:     1,
 		batchSize:       1000,
@@ -45,6 +51,8 @@ func newConsumerConfig(opts ...ConsumerOption) (*consumerConfig, error) {
 		opt.setConsumerConfig(cfg)
 	}
 
+	cfg.setSecurityProtocol(cfg.configMap)
+
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
@@ -73,7 +81,9 @@ type Topics []string
 
 func (t Topics) setConsumerConfig(o *consumerConfig) { o.topics = t }
 
-// PollTimeout defines the timeout for the consumer read timeout.
+// PollTimeout defines the maximum time a poll for the next event blocks.
+// The consumer stops handling events as soon as the context is cancelled,
+// but waits for the poll in flight before it unsubscribes.
 type PollTimeout time.Duration
 
 func (pt PollTimeout) setConsumerConfig(o *consumerConfig) {
@@ -15,11 +15,12 @@ import (
 )
 
 var (
-	testTopics  = Topics{"test-topic"}
-	testBrokers = Brokers{"localhost:9092"}
-	errHandler  = ErrorHandler(func(err error) error { return err })
-	testTimeout = time.Second
-	defaultOpts = []ConsumerOption{
+	testTopics      = Topics{"test-topic"}
+	testBrokers     = Brokers{"localhost:9092"}
+	errHandler      = ErrorHandler(func(err error) error { return err })
+	testTimeout     = time.Second
+	testPollTimeout = int(testTimeout.Milliseconds())
+	defaultOpts     = []ConsumerOption{
 		testTopics,
 		testBrokers,
 		errHandler,
@@ -134,7 +135,7 @@ func TestConsumerLifecycle(t *testing.T) {
 
 		mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 		mockKafka.On("Unsubscribe").Return(nil)
-		mockKafka.On("ReadMessage", testTimeout).Return(newFakeKafkaMessage(), nil)
+		mockKafka.On("Poll", testPollTimeout).Return(newFakeKafkaMessage())
 		mockKafka.On("Commit").Return(nil, nil)
 		mockKafka.On("Close").Return(errors.New("error in close"))
 
@@ -150,7 +151,7 @@ func TestConsumerLifecycle(t *testing.T) {
 
 		mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 		mockKafka.On("Unsubscribe").Return(nil)
-		mockKafka.On("ReadMessage", testTimeout).Return(newFakeKafkaMessage(), nil)
+		mockKafka.On("Poll", testPollTimeout).Return(newFakeKafkaMessage())
 		mockKafka.On("Commit").Return(nil, nil)
 		mockKafka.On("Close").Return(nil)
 
@@ -195,7 +196,8 @@ func TestConsumerUnsubscribeError(t *testing.T) {
 
 	mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 	mockKafka.On("Unsubscribe").Return(unsubError)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Close").Return(nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Commit").Return(nil, nil)
 
 	consumer.handler = handler
@@ -225,7 +227,7 @@ func TestConsumerHandleMessage(t *testing.T) {
 	mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 	mockKafka.On("Unsubscribe").Return(nil)
 	mockKafka.On("Commit").Return(nil, nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Close").Return(nil)
 
 	consumer.handler = handler
@@ -269,8 +271,9 @@ func TestConsumerHandleMessageError(t *testing.T) {
 
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
+			mockKafka.On("Close").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 
 			consumer.handler = handler
 			err := consumer.Run(ctx)
@@ -308,7 +311,8 @@ func TestConsumerErrorCallback(t *testing.T) {
 			expect := errors.New("error in handler")
 
 			errHandler := ErrorHandler(func(err error) error {
-				assert.Equal(t, expect, err)
+				assert.ErrorIs(t, err, expect)
+				assert.ErrorIs(t, err, ErrHandler)
 
 				return err
 			})
@@ -324,8 +328,9 @@ func TestConsumerErrorCallback(t *testing.T) {
 
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
+			mockKafka.On("Close").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 
 			consumer.handler = handler
 
@@ -337,7 +342,7 @@ func TestConsumerErrorCallback(t *testing.T) {
 	}
 }
 
-func TestConsumerReadMessageTimeout(t *testing.T) {
+func TestConsumerPollTimeout(t *testing.T) {
 	t.Parallel()
 
 	testcases := []struct {
@@ -383,9 +388,9 @@ func TestConsumerReadMessageTimeout(t *testing.T) {
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil).Once()
-			mockKafka.On("ReadMessage", testTimeout).Return(nil, expect).Once()
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km).Once()
+			mockKafka.On("Poll", testPollTimeout).Return(expect).Once()
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 			mockKafka.On("Close").Return(nil)
 
 			consumer.handler = handler
@@ -427,9 +432,10 @@ func TestConsumerKafkaError(t *testing.T) {
 
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
+			mockKafka.On("Close").Return(nil)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil).Once()
-			mockKafka.On("ReadMessage", testTimeout).Return(nil, expect).Once()
+			mockKafka.On("Poll", testPollTimeout).Return(km).Once()
+			mockKafka.On("Poll", testPollTimeout).Return(expect).Once()
 
 			err := consumer.Run(ctx)
 			assert.Error(t, err)
@@ -451,7 +457,7 @@ func TestConsumerMiddlewareExecutionOrder(t *testing.T) {
 	mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 	mockKafka.On("Unsubscribe").Return(nil)
 	mockKafka.On("Commit").Return(nil, nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Close").Return(nil)
 
 	handler := HandlerFunc(func(ctx context.Context, msg *Message) error {
@@ -503,7 +509,7 @@ func TestConsumerAsync(t *testing.T) {
 	}).Return(nil)
 	mockKafka.On("Unsubscribe").Return(nil)
 	mockKafka.On("StoreOffsets", mock.Anything).Return(nil, nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Commit").Return(nil, nil)
 	mockKafka.On("Close").Return(nil)
 
@@ -550,7 +556,7 @@ func TestConsumerAsync_StopOffsetOnError(t *testing.T) {
 		cb(nil, kafka.AssignedPartitions{Partitions: partitions})
 	}).Return(nil)
 	mockKafka.On("Unsubscribe").Return(nil)
-	mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+	mockKafka.On("Poll", testPollTimeout).Return(km)
 	mockKafka.On("Commit").Return(nil, nil)
 	mockKafka.On("Close").Return(nil)
 
@@ -577,7 +583,7 @@ func TestConsumerAsync_StopOffsetOnError(t *testing.T) {
 	consumer.handler = handler
 
 	err := consumer.Run(ctx)
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, assert.AnError)
 
 	mockKafka.AssertExpectations(t)
 }
@@ -619,9 +625,10 @@ func TestConsumerStoreOffsetsError(t *testing.T) {
 
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
+			mockKafka.On("Close").Return(nil)
 			mockKafka.On("StoreOffsets", mock.Anything).Return(nil, expect)
 			mockKafka.On("Commit").Return(nil, nil)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 
 			consumer.handler = handler
 			assignPartitions(t, consumer, mockKafka, testTopics[0], 1)
@@ -673,9 +680,10 @@ func TestConsumerCommitError(t *testing.T) {
 
 			mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
 			mockKafka.On("Unsubscribe").Return(nil)
+			mockKafka.On("Close").Return(nil)
 			mockKafka.On("StoreOffsets", mock.Anything).Return(nil, nil)
 			mockKafka.On("Commit").Return(nil, expect)
-			mockKafka.On("ReadMessage", testTimeout).Return(km, nil)
+			mockKafka.On("Poll", testPollTimeout).Return(km)
 
 			consumer.handler = handler
 			assignPartitions(t, consumer, mockKafka, testTopics[0], 1)
@@ -29,6 +29,11 @@
 // xkafka.ErrorHandler is called for every error that is not handled by the handler or
 // the middlewares. It is also called for errors returned by underlying Kafka client.
 //
+// Errors passed to the xkafka.ErrorHandler are classified by kind: ErrFatal, ErrRetryable,
+// ErrAuthentication, ErrTopicNotFound and ErrHandler, which can be checked with errors.Is.
+// Error handling policies can be composed with xkafka.ChainErrorHandlers, using
+// xkafka.StopOnFatal, xkafka.LogAndContinue and xkafka.ThresholdWithinWindow.
+//
 // ### Sequential Processing
 // Sequential processing is the default mode. It is same as xkafka.Concurrency(1).
 //
@@ -47,4 +52,39 @@
 //
 // NOTE: Enabling ManualCommit will add an overhead to each message. It is
 // recommended to use ManualCommit only when necessary.
+//
+// ### Events
+// The consumers poll kafka for events in a separate goroutine, and stop as soon as
+// the context is cancelled. Messages are passed to the handler, errors to the
+// ErrorHandler, and the other events to typed callbacks: StatsCallback,
+// PartitionEOFCallback and OAuthBearerTokenRefreshCallback.
+//
+// ### Batch Middleware
+// Any message middleware can be used with a BatchConsumer by lifting it with
+// xkafka.Lift. LiftFanOut runs the middleware for every message and fails the batch
+// with all the errors, while LiftFailFast stops the batch at the first error.
+//
+// ## Pipeline
+// xkafka.Pipeline builds the Consumer handler from declarative stages, like Filter
+// and Map, terminated by a Handler or a Producer. With Flow.To, the consumed message
+// is acknowledged only after it is delivered to the output topic, which makes a
+// topic-to-topic bridge a few lines of code.
+//
+// ## Group
+// xkafka.Group runs many consumers and producers in one process. Failed members
+// are restarted according to a RestartPolicy, the health of all members is reported
+// as an xpod.Checker, and members are shut down in the reverse order they were added.
+//
+// ## Security
+// xkafka.SASL and xkafka.TLS configure authentication and encryption for consumers
+// and producers, and set `security.protocol` unless it is set with ConfigMap. Both
+// can be loaded with xload. With a SASL TokenProvider, OAUTHBEARER tokens are
+// refreshed automatically, and refresh failures are retried by the client.
+// TLS.ReloadOnRotation re-creates the clients when certificate files are rotated.
+//
+// ## Message ID
+// The Producer generates an ID for every published message without one, and
+// carries it in the `xkafka-message-id` header. The consumers restore the ID
+// from the header, so the same message can be identified across services for
+// logging, deduplication and tracing.
 package xkafka
@@ -2,6 +2,9 @@ package xkafka
 
 import (
 	"errors"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
@@ -12,8 +15,82 @@ var (
 	// ErrRequiredOption is returned when a required option is
 	// not provided.
 	ErrRequiredOption = errors.New("xkafka: required option not provided")
+	// ErrFatal is the error kind for fatal Kafka client errors.
+	// The client can not be used anymore, and must be re-created.
+	ErrFatal = errors.New("xkafka: fatal error")
+	// ErrAuthentication is the error kind for authentication
+	// and authorization failures.
+	ErrAuthentication = errors.New("xkafka: authentication error")
+	// ErrTopicNotFound is the error kind for unknown topics or partitions.
+	ErrTopicNotFound = errors.New("xkafka: topic not found")
+	// ErrHandler is the error kind for errors returned by the handler.
+	ErrHandler = errors.New("xkafka: handler error")
 )
 
+// Error is an error classified by its kind, one of ErrFatal, ErrRetryable,
+// ErrAuthentication, ErrTopicNotFound or ErrHandler.
+// The consumers wrap errors with Error before calling the ErrorHandler,
+// so the kind can be checked with errors.Is, while errors.Is and errors.As
+// still match the underlying error.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// Error returns the message of the underlying error.
+func (e *Error) Error() string { return e.Err.Error() }
+
+// Unwrap returns both the kind and the underlying error.
+func (e *Error) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// classifyError wraps a Kafka client error with its kind. Errors that
+// do not match a kind are returned as is.
+func classifyError(err error) error {
+	var kerr kafka.Error
+	if !errors.As(err, &kerr) {
+		return err
+	}
+
+	var kind error
+
+	switch {
+	case kerr.IsFatal():
+		kind = ErrFatal
+	case isAuthErrorCode(kerr.Code()):
+		kind = ErrAuthentication
+	case kerr.Code() == kafka.ErrUnknownTopicOrPart, kerr.Code() == kafka.ErrUnknownTopic:
+		kind = ErrTopicNotFound
+	case kerr.IsRetriable(), kerr.IsTimeout():
+		kind = ErrRetryable
+	default:
+		return err
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
+
+func isAuthErrorCode(code kafka.ErrorCode) bool {
+	switch code {
+	case kafka.ErrAuthentication,
+		kafka.ErrSaslAuthenticationFailed,
+		kafka.ErrTopicAuthorizationFailed,
+		kafka.ErrGroupAuthorizationFailed,
+		kafka.ErrClusterAuthorizationFailed:
+		return true
+	}
+
+	return false
+}
+
+// handlerError wraps a non-nil error returned by the handler with ErrHandler.
+func handlerError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Kind: ErrHandler, Err: err}
+}
+
 // isErrNoOffset reports whether err is librdkafka's ErrNoOffset
 // ("Local: No offset stored"), which a manual Commit returns when there is
 // nothing to commit for the current assignment. This is benign: with
@@ -28,6 +105,11 @@ func isErrNoOffset(err error) bool {
 }
 
 // ErrorHandler is a callback function that is called when an error occurs.
+// Returning a non-nil error stops the consumer.
+//
+// ErrorHandler is called with a nil error after every successfully
+// handled message. All ErrorHandler implementations must return nil
+// for a nil error.
 type ErrorHandler func(err error) error
 
 func (h ErrorHandler) setConsumerConfig(o *consumerConfig) { o.errorHandler = h }
@@ -36,3 +118,83 @@ func (h ErrorHandler) setProducerConfig(o *producerConfig) { o.errorHandler = h
 
 // NoopErrorHandler is an ErrorHandler that passes the error through.
 func NoopErrorHandler(err error) error { return err }
+
+// ChainErrorHandlers combines ErrorHandlers into one. The handlers are
+// called in order, until one of them returns a non-nil error.
+func ChainErrorHandlers(handlers ...ErrorHandler) ErrorHandler {
+	return func(err error) error {
+		for _, h := range handlers {
+			if ferr := h(err); ferr != nil {
+				return ferr
+			}
+		}
+
+		return nil
+	}
+}
+
+// StopOn is an ErrorHandler that passes through the errors that match
+// any of the targets, and ignores the rest.
+func StopOn(targets ...error) ErrorHandler {
+	return func(err error) error {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// StopOnFatal is an ErrorHandler that passes through ErrFatal errors,
+// and ignores the rest.
+func StopOnFatal() ErrorHandler {
+	return StopOn(ErrFatal)
+}
+
+// LogAndContinue is an ErrorHandler that logs the errors and ignores them.
+func LogAndContinue(logger *slog.Logger) ErrorHandler {
+	return func(err error) error {
+		if err != nil {
+			logger.Error("[xkafka] error", "error", err)
+		}
+
+		return nil
+	}
+}
+
+// ThresholdWithinWindow is an ErrorHandler that ignores errors until more
+// than n errors occur within the sliding window d. The error that crosses
+// the threshold is passed through.
+func ThresholdWithinWindow(n int, d time.Duration) ErrorHandler {
+	var (
+		mu   sync.Mutex
+		seen []time.Time
+	)
+
+	return func(err error) error {
+		if err == nil {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+
+		// drop errors that are out of the window
+		i := 0
+		for i < len(seen) && now.Sub(seen[i]) > d {
+			i++
+		}
+
+		seen = append(seen[i:], now)
+
+		if len(seen) > n {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,135 @@
+package xkafka
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	testcases := []struct {
+		name   string
+		err    error
+		expect error
+	}{
+		{
+			name:   "fatal",
+			err:    kafka.NewError(kafka.ErrFatal, "fatal", true),
+			expect: ErrFatal,
+		},
+		{
+			name:   "authentication",
+			err:    kafka.NewError(kafka.ErrSaslAuthenticationFailed, "sasl", false),
+			expect: ErrAuthentication,
+		},
+		{
+			name:   "authorization",
+			err:    kafka.NewError(kafka.ErrTopicAuthorizationFailed, "topic auth", false),
+			expect: ErrAuthentication,
+		},
+		{
+			name:   "topic not found",
+			err:    kafka.NewError(kafka.ErrUnknownTopicOrPart, "unknown topic", false),
+			expect: ErrTopicNotFound,
+		},
+		{
+			name:   "timeout",
+			err:    kafka.NewError(kafka.ErrTimedOut, "timed out", false),
+			expect: ErrRetryable,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := classifyError(tc.err)
+
+			assert.ErrorIs(t, err, tc.expect)
+			assert.ErrorIs(t, err, tc.err)
+			assert.EqualError(t, err, tc.err.Error())
+
+			var kerr kafka.Error
+			assert.ErrorAs(t, err, &kerr)
+		})
+	}
+
+	t.Run("unclassified", func(t *testing.T) {
+		err := kafka.NewError(kafka.ErrUnknown, "unknown", false)
+		assert.Equal(t, err, classifyError(err))
+		assert.Equal(t, assert.AnError, classifyError(assert.AnError))
+	})
+}
+
+func TestHandlerError(t *testing.T) {
+	assert.NoError(t, handlerError(nil))
+
+	err := handlerError(assert.AnError)
+	assert.ErrorIs(t, err, ErrHandler)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.EqualError(t, err, assert.AnError.Error())
+}
+
+func TestStopOnFatal(t *testing.T) {
+	h := StopOnFatal()
+
+	assert.NoError(t, h(nil))
+	assert.NoError(t, h(handlerError(assert.AnError)))
+
+	fatal := classifyError(kafka.NewError(kafka.ErrFatal, "fatal", true))
+	assert.ErrorIs(t, h(fatal), ErrFatal)
+}
+
+func TestStopOn(t *testing.T) {
+	h := StopOn(ErrAuthentication, ErrTopicNotFound)
+
+	assert.NoError(t, h(nil))
+	assert.NoError(t, h(assert.AnError))
+
+	err := classifyError(kafka.NewError(kafka.ErrUnknownTopicOrPart, "unknown topic", false))
+	assert.ErrorIs(t, h(err), ErrTopicNotFound)
+}
+
+func TestLogAndContinue(t *testing.T) {
+	h := LogAndContinue(slog.Default())
+
+	assert.NoError(t, h(nil))
+	assert.NoError(t, h(assert.AnError))
+}
+
+func TestThresholdWithinWindow(t *testing.T) {
+	h := ThresholdWithinWindow(2, 50*time.Millisecond)
+
+	assert.NoError(t, h(nil))
+	assert.NoError(t, h(assert.AnError))
+	assert.NoError(t, h(assert.AnError))
+	assert.ErrorIs(t, h(assert.AnError), assert.AnError)
+
+	time.Sleep(60 * time.Millisecond)
+
+	assert.NoError(t, h(assert.AnError))
+}
+
+func TestChainErrorHandlers(t *testing.T) {
+	var logged []error
+
+	logAll := ErrorHandler(func(err error) error {
+		if err != nil {
+			logged = append(logged, err)
+		}
+
+		return nil
+	})
+
+	h := ChainErrorHandlers(logAll, StopOnFatal(), ThresholdWithinWindow(1, time.Minute))
+
+	assert.NoError(t, h(nil))
+	assert.NoError(t, h(handlerError(assert.AnError)))
+
+	fatal := classifyError(kafka.NewError(kafka.ErrFatal, "fatal", true))
+	assert.ErrorIs(t, h(fatal), ErrFatal)
+
+	assert.ErrorIs(t, h(handlerError(assert.AnError)), assert.AnError)
+	assert.Len(t, logged, 3)
+}
@@ -1,7 +1,12 @@
 package xkafka
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
 )
 
 func ExampleConsumer() {
@@ -147,3 +152,158 @@ func ExampleProducer_AsyncPublish() {
 	// cancel the context to stop the producer
 	cancel()
 }
+
+func ExampleChainErrorHandlers() {
+	handler := HandlerFunc(func(ctx context.Context, msg *Message) error {
+		msg.AckSuccess()
+
+		return nil
+	})
+
+	// log every error, stop on fatal errors,
+	// or when more than 10 errors occur within a minute
+	errorHandler := ChainErrorHandlers(
+		LogAndContinue(slog.Default()),
+		StopOnFatal(),
+		ThresholdWithinWindow(10, time.Minute),
+	)
+
+	consumer, err := NewConsumer("consumer-id", handler,
+		Topics{"test"},
+		Brokers{"localhost:9092"},
+		errorHandler,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := consumer.Run(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+func ExamplePipeline() {
+	ctx := context.Background()
+
+	producer, err := NewProducer(
+		"bridge-producer",
+		Brokers{"localhost:9092"},
+		ErrorHandler(NoopErrorHandler),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	defer producer.Close()
+
+	// the handler is set by the pipeline
+	consumer, err := NewConsumer("bridge-consumer", nil,
+		Topics{"orders"},
+		Brokers{"localhost:9092"},
+		ErrorHandler(NoopErrorHandler),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	err = Pipeline(consumer).
+		// drop test orders
+		Filter(func(msg *Message) bool {
+			return !bytes.HasPrefix(msg.Key, []byte("test-"))
+		}).
+		// enrich with the source topic
+		Map(func(ctx context.Context, msg *Message) error {
+			msg.SetHeader("source-topic", []byte(msg.Topic))
+
+			return nil
+		}).
+		// offsets are stored only after the message is delivered
+		To(producer, "orders-bridged").
+		Run(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleGroup() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	producer, err := NewProducer(
+		"group-producer",
+		Brokers{"localhost:9092"},
+		ErrorHandler(NoopErrorHandler),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	group := NewGroup(
+		"kafka",
+		// restart failed consumers, up to 5 times
+		ExponentialRestart(time.Second, time.Minute, 5),
+		ShutdownTimeout(10*time.Second),
+	)
+
+	// producers are added first, so they are closed last
+	group.Add("producer", producer.Run)
+
+	group.Add("orders", func(ctx context.Context) error {
+		// consumers are created on every (re)start
+		consumer, err := NewConsumer("orders", HandlerFunc(func(ctx context.Context, msg *Message) error {
+			return producer.Publish(ctx, &Message{Topic: "orders-audit", Value: msg.Value})
+		}),
+			Topics{"orders"},
+			Brokers{"localhost:9092"},
+			ErrorHandler(StopOnFatal()),
+		)
+		if err != nil {
+			return err
+		}
+
+		return consumer.Run(ctx)
+	})
+
+	// group can be added to xpod.Options.ReadyCheckers
+
+	if err := group.Run(ctx); err != nil {
+		panic(err)
+	}
+}
+
+func ExampleSASL() {
+	// fetch tokens from the identity provider
+	fetchToken := func(ctx context.Context) (OAuthBearerToken, error) {
+		return OAuthBearerToken{
+			TokenValue: "token",
+			Expiration: time.Now().Add(time.Hour),
+			Principal:  "service",
+		}, nil
+	}
+
+	handler := HandlerFunc(func(ctx context.Context, msg *Message) error {
+		msg.AckSuccess()
+
+		return nil
+	})
+
+	// SASL and TLS can also be loaded with xload, e.g. from environment variables
+	consumer, err := NewConsumer("consumer-id", handler,
+		Topics{"test"},
+		Brokers{"localhost:9093"},
+		ErrorHandler(func(err error) error { return err }),
+		SASL{TokenProvider: fetchToken},
+		TLS{
+			CAFile:   "/etc/kafka/ca.pem",
+			CertFile: "/etc/kafka/client.pem",
+			KeyFile:  "/etc/kafka/client.key",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := consumer.Run(context.Background()); err != nil {
+		panic(err)
+	}
+}
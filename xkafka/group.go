@@ -0,0 +1,288 @@
+package xkafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrGroupMemberDown is returned by Group.Check when a member is not running.
+	ErrGroupMemberDown = errors.New("xkafka: group member is not running")
+	// ErrGroupMemberExited is the error of a member that returned
+	// without error while the Group was still running.
+	ErrGroupMemberExited = errors.New("xkafka: group member exited")
+)
+
+// RunFunc runs a Group member until the context is cancelled or it fails.
+// A Consumer or BatchConsumer cannot be run again once closed, so a RunFunc
+// that should be restarted must create a new instance on every call.
+type RunFunc func(ctx context.Context) error
+
+// MemberState is an enum for the state of a Group member.
+type MemberState int
+
+// MemberState enums.
+const (
+	MemberRunning MemberState = iota
+	MemberRestarting
+	MemberStopped
+	MemberFailed
+)
+
+// String returns the string representation of the MemberState.
+func (s MemberState) String() string {
+	return [...]string{"RUNNING", "RESTARTING", "STOPPED", "FAILED"}[s]
+}
+
+// MemberStatus is a snapshot of the state of a Group member.
+type MemberStatus struct {
+	Name     string
+	State    MemberState
+	Restarts int
+	Err      error
+}
+
+// GroupOption is an interface for Group options.
+type GroupOption interface{ setGroupConfig(*groupConfig) }
+
+type groupConfig struct {
+	restartPolicy   RestartPolicy
+	shutdownTimeout time.Duration
+}
+
+func (st ShutdownTimeout) setGroupConfig(o *groupConfig) {
+	o.shutdownTimeout = time.Duration(st)
+}
+
+// RestartPolicy decides if a failed member is restarted, and after
+// what delay. restarts is the number of times the member has been
+// restarted so far.
+type RestartPolicy func(restarts int, err error) (time.Duration, bool)
+
+func (p RestartPolicy) setGroupConfig(o *groupConfig) { o.restartPolicy = p }
+
+// NoRestart is a RestartPolicy that never restarts failed members.
+func NoRestart() RestartPolicy {
+	return func(int, error) (time.Duration, bool) { return 0, false }
+}
+
+// ExponentialRestart is a RestartPolicy that restarts failed members
+// with an exponential delay, starting at initial and capped at maxDelay.
+// Members are restarted at most maxRestarts times, or forever if zero.
+// Members failing with ErrFatal are never restarted.
+func ExponentialRestart(initial, maxDelay time.Duration, maxRestarts int) RestartPolicy {
+	return func(restarts int, err error) (time.Duration, bool) {
+		if errors.Is(err, ErrFatal) || (maxRestarts > 0 && restarts >= maxRestarts) {
+			return 0, false
+		}
+
+		delay := initial
+		for range restarts {
+			if delay >= maxDelay/2 {
+				return maxDelay, true
+			}
+
+			delay *= 2
+		}
+
+		return min(delay, maxDelay), true
+	}
+}
+
+// Group supervises consumers, producers and other components that run
+// in the same process. It implements xpod.Checker, and reports unhealthy
+// while any member is not running.
+//
+// Members are started concurrently, without waiting for the previous ones
+// to be running, and shut down in the reverse order they are added. Add
+// producers before the consumers that publish with them, so that they are
+// closed only after the consumers have stopped.
+//
+// A member that returns without error while the Group is running is
+// treated as failed with ErrGroupMemberExited, and is restarted or stops
+// the Group according to the RestartPolicy.
+type Group struct {
+	name    string
+	config  *groupConfig
+	members []*member
+}
+
+type member struct {
+	name   string
+	run    RunFunc
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	state    MemberState
+	restarts int
+	err      error
+}
+
+// NewGroup creates a new Group. By default, failed members are not
+// restarted and the Group stops with the error of the first failure.
+func NewGroup(name string, opts ...GroupOption) *Group {
+	cfg := &groupConfig{
+		restartPolicy:   NoRestart(),
+		shutdownTimeout: 10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt.setGroupConfig(cfg)
+	}
+
+	return &Group{name: name, config: cfg}
+}
+
+// Add adds a member to the Group. Add must be called before Run.
+//
+// Consumer.Run, BatchConsumer.Run and Producer.Run can be added directly
+// when the member does not need to be restarted.
+func (g *Group) Add(name string, run RunFunc) {
+	g.members = append(g.members, &member{name: name, run: run, state: MemberStopped})
+}
+
+// Run starts all the members and blocks until the context is cancelled
+// or a member fails and is not restarted. The members are then shut down
+// in the reverse order they were added, each waiting up to the
+// ShutdownTimeout. Run returns the error of the failed member, if any.
+func (g *Group) Run(ctx context.Context) error {
+	// members are cancelled one by one during shutdown,
+	// so they only inherit the values of ctx.
+	base := context.WithoutCancel(ctx)
+	failed := make(chan error, len(g.members))
+
+	for _, m := range g.members {
+		var mctx context.Context
+
+		mctx, m.cancel = context.WithCancel(base)
+		m.done = make(chan struct{})
+
+		go g.supervise(mctx, m, failed)
+	}
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+	case err = <-failed:
+	}
+
+	g.shutdown()
+
+	return err
+}
+
+func (g *Group) supervise(ctx context.Context, m *member, failed chan<- error) {
+	defer close(m.done)
+
+	for {
+		m.set(MemberRunning, nil, false)
+
+		err := m.run(ctx)
+		if ctx.Err() != nil {
+			m.set(MemberStopped, err, false)
+
+			return
+		}
+
+		if err == nil {
+			err = ErrGroupMemberExited
+		}
+
+		delay, ok := g.config.restartPolicy(m.status().Restarts, err)
+		if !ok {
+			m.set(MemberFailed, err, false)
+
+			failed <- fmt.Errorf("xkafka: group member %q: %w", m.name, err)
+
+			return
+		}
+
+		m.set(MemberRestarting, err, true)
+
+		select {
+		case <-ctx.Done():
+			m.set(MemberStopped, err, false)
+
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (g *Group) shutdown() {
+	for i := len(g.members) - 1; i >= 0; i-- {
+		m := g.members[i]
+
+		m.cancel()
+
+		select {
+		case <-m.done:
+		case <-time.After(g.config.shutdownTimeout):
+		}
+	}
+}
+
+// Name returns the name of the Group.
+func (g *Group) Name() string { return g.name }
+
+// Check returns ErrGroupMemberDown, joined with the last error of
+// the member, for each member that is not running.
+func (g *Group) Check(_ *http.Request) error {
+	var errs []error
+
+	for _, s := range g.Members() {
+		if s.State == MemberRunning {
+			continue
+		}
+
+		err := fmt.Errorf("%w: %s is %s", ErrGroupMemberDown, s.Name, s.State)
+		if s.Err != nil {
+			err = fmt.Errorf("%w: %w", err, s.Err)
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Members returns the status of all the members, in the order they were added.
+func (g *Group) Members() []MemberStatus {
+	statuses := make([]MemberStatus, 0, len(g.members))
+
+	for _, m := range g.members {
+		statuses = append(statuses, m.status())
+	}
+
+	return statuses
+}
+
+func (m *member) set(state MemberState, err error, restart bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state = state
+	m.err = err
+
+	if restart {
+		m.restarts++
+	}
+}
+
+func (m *member) status() MemberStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return MemberStatus{
+		Name:     m.name,
+		State:    m.state,
+		Restarts: m.restarts,
+		Err:      m.err,
+	}
+}
@@ -0,0 +1,213 @@
+package xkafka
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupShutdownOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		stopped []string
+		started sync.WaitGroup
+	)
+
+	runUntilDone := func(name string) RunFunc {
+		return func(ctx context.Context) error {
+			started.Done()
+			<-ctx.Done()
+
+			mu.Lock()
+			stopped = append(stopped, name)
+			mu.Unlock()
+
+			return nil
+		}
+	}
+
+	g := NewGroup("test-group")
+	g.Add("producer", runUntilDone("producer"))
+	g.Add("consumer-1", runUntilDone("consumer-1"))
+	g.Add("consumer-2", runUntilDone("consumer-2"))
+
+	started.Add(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- g.Run(ctx) }()
+
+	started.Wait()
+
+	assert.Equal(t, "test-group", g.Name())
+	assert.NoError(t, g.Check(nil))
+
+	cancel()
+
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"consumer-2", "consumer-1", "producer"}, stopped)
+	assert.ErrorIs(t, g.Check(nil), ErrGroupMemberDown)
+}
+
+func TestGroupMemberFailure(t *testing.T) {
+	t.Parallel()
+
+	var otherStopped atomic.Bool
+
+	g := NewGroup("test-group")
+	g.Add("other", func(ctx context.Context) error {
+		<-ctx.Done()
+		otherStopped.Store(true)
+
+		return nil
+	})
+	g.Add("failing", func(ctx context.Context) error {
+		return assert.AnError
+	})
+
+	err := g.Run(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, `"failing"`)
+	assert.True(t, otherStopped.Load())
+
+	statuses := g.Members()
+	assert.Equal(t, MemberStopped, statuses[0].State)
+	assert.Equal(t, MemberFailed, statuses[1].State)
+	assert.ErrorIs(t, statuses[1].Err, assert.AnError)
+
+	err = g.Check(nil)
+	assert.ErrorIs(t, err, ErrGroupMemberDown)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestGroupMemberExited(t *testing.T) {
+	t.Parallel()
+
+	g := NewGroup("test-group")
+	g.Add("other", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return nil
+	})
+	g.Add("exiting", func(ctx context.Context) error {
+		return nil
+	})
+
+	err := g.Run(context.Background())
+	assert.ErrorIs(t, err, ErrGroupMemberExited)
+	assert.ErrorContains(t, err, `"exiting"`)
+
+	statuses := g.Members()
+	assert.Equal(t, MemberStopped, statuses[0].State)
+	assert.Equal(t, MemberFailed, statuses[1].State)
+	assert.ErrorIs(t, statuses[1].Err, ErrGroupMemberExited)
+
+	t.Run("restarted", func(t *testing.T) {
+		var runs atomic.Int32
+
+		g := NewGroup("test-group", ExponentialRestart(time.Millisecond, time.Millisecond, 2))
+		g.Add("exiting", func(ctx context.Context) error {
+			runs.Add(1)
+
+			return nil
+		})
+
+		err := g.Run(context.Background())
+		assert.ErrorIs(t, err, ErrGroupMemberExited)
+		assert.EqualValues(t, 3, runs.Load())
+	})
+}
+
+func TestGroupRestart(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+
+	g := NewGroup(
+		"test-group",
+		ExponentialRestart(time.Millisecond, 5*time.Millisecond, 3),
+	)
+	g.Add("flaky", func(ctx context.Context) error {
+		runs.Add(1)
+
+		return assert.AnError
+	})
+
+	err := g.Run(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.EqualValues(t, 4, runs.Load())
+	assert.Equal(t, 3, g.Members()[0].Restarts)
+}
+
+func TestGroupRestartRecovers(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+
+	g := NewGroup("test-group", ExponentialRestart(time.Millisecond, time.Millisecond, 0))
+	g.Add("flaky", func(ctx context.Context) error {
+		if runs.Add(1) < 3 {
+			return assert.AnError
+		}
+
+		<-ctx.Done()
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- g.Run(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		return g.Check(nil) == nil
+	}, testTimeout, time.Millisecond)
+
+	cancel()
+
+	require.NoError(t, <-done)
+	assert.Equal(t, 2, g.Members()[0].Restarts)
+}
+
+func TestGroupShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	g := NewGroup("test-group", ShutdownTimeout(10*time.Millisecond))
+	g.Add("stuck", func(ctx context.Context) error {
+		select {}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, g.Run(ctx))
+}
+
+func TestExponentialRestart(t *testing.T) {
+	p := ExponentialRestart(time.Second, 5*time.Second, 4)
+
+	for restarts, expect := range []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second,
+	} {
+		delay, ok := p(restarts, assert.AnError)
+		assert.True(t, ok)
+		assert.Equal(t, expect, delay)
+	}
+
+	_, ok := p(4, assert.AnError)
+	assert.False(t, ok)
+
+	fatal := classifyError(kafka.NewError(kafka.ErrFatal, "fatal", true))
+	_, ok = p(0, fatal)
+	assert.False(t, ok)
+}
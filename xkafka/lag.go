@@ -0,0 +1,122 @@
+package xkafka
+
+import (
+	"context"
+	"sort"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// PartitionLag is the lag of the consumer group on an assigned partition.
+type PartitionLag struct {
+	Topic     string
+	Partition int32
+	// Committed is the committed offset of the group, or -1 if the
+	// group has not committed an offset for the partition.
+	Committed int64
+	// HighWatermark is the offset of the next message in the partition.
+	HighWatermark int64
+	// Lag is the number of messages after the committed offset, or after
+	// the low watermark if no offset is committed.
+	Lag int64
+}
+
+// Name returns the name of the consumer, which is also its group ID.
+func (c *Consumer) Name() string { return c.name }
+
+// Lag returns the lag of the partitions currently assigned to the consumer.
+// It queries the brokers for the committed offsets and the watermarks,
+// with the MetadataTimeout.
+func (c *Consumer) Lag(ctx context.Context) ([]PartitionLag, error) {
+	c.mu.Lock()
+	tps := assignedPartitions(c.activePartitions)
+	c.mu.Unlock()
+
+	return partitionLags(ctx, c.kafka, c.config, tps)
+}
+
+// Name returns the name of the consumer, which is also its group ID.
+func (c *BatchConsumer) Name() string { return c.name }
+
+// Lag returns the lag of the partitions currently assigned to the consumer.
+// It queries the brokers for the committed offsets and the watermarks,
+// with the MetadataTimeout.
+func (c *BatchConsumer) Lag(ctx context.Context) ([]PartitionLag, error) {
+	c.mu.Lock()
+	tps := assignedPartitions(c.activePartitions)
+	c.mu.Unlock()
+
+	return partitionLags(ctx, c.kafka, c.config, tps)
+}
+
+func assignedPartitions(active map[string]map[int32]struct{}) []kafka.TopicPartition {
+	tps := make([]kafka.TopicPartition, 0, len(active))
+
+	for topic, partitions := range active {
+		for partition := range partitions {
+			tps = append(tps, kafka.TopicPartition{Topic: &topic, Partition: partition})
+		}
+	}
+
+	sort.Slice(tps, func(i, j int) bool {
+		if *tps[i].Topic != *tps[j].Topic {
+			return *tps[i].Topic < *tps[j].Topic
+		}
+
+		return tps[i].Partition < tps[j].Partition
+	})
+
+	return tps
+}
+
+func partitionLags(
+	ctx context.Context,
+	client consumerClient,
+	cfg *consumerConfig,
+	tps []kafka.TopicPartition,
+) ([]PartitionLag, error) {
+	if len(tps) == 0 {
+		return nil, nil
+	}
+
+	timeout := int(cfg.metadataTimeout.Milliseconds())
+
+	committed, err := client.Committed(tps, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	lags := make([]PartitionLag, 0, len(committed))
+
+	for _, tp := range committed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if tp.Topic == nil {
+			continue
+		}
+
+		low, high, err := client.QueryWatermarkOffsets(*tp.Topic, tp.Partition, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		lag := PartitionLag{
+			Topic:         *tp.Topic,
+			Partition:     tp.Partition,
+			Committed:     -1,
+			HighWatermark: high,
+			Lag:           high - low,
+		}
+
+		if tp.Offset >= 0 {
+			lag.Committed = int64(tp.Offset)
+			lag.Lag = max(high-lag.Committed, 0)
+		}
+
+		lags = append(lags, lag)
+	}
+
+	return lags, nil
+}
@@ -0,0 +1,65 @@
+package xkafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerLag(t *testing.T) {
+	t.Parallel()
+
+	consumer, mockKafka := newTestConsumer(t, defaultOpts...)
+
+	topic := "test-topic"
+	timeout := int(consumer.config.metadataTimeout.Milliseconds())
+
+	lags, err := consumer.Lag(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, lags)
+
+	consumer.onPartitionsAssigned([]kafka.TopicPartition{
+		{Topic: &topic, Partition: 1},
+		{Topic: &topic, Partition: 0},
+	})
+
+	mockKafka.On("Committed", []kafka.TopicPartition{
+		{Topic: &topic, Partition: 0},
+		{Topic: &topic, Partition: 1},
+	}, timeout).Return([]kafka.TopicPartition{
+		{Topic: &topic, Partition: 0, Offset: 90},
+		{Topic: &topic, Partition: 1, Offset: kafka.OffsetInvalid},
+	}, nil)
+	mockKafka.On("QueryWatermarkOffsets", topic, int32(0), timeout).Return(int64(10), int64(100), nil)
+	mockKafka.On("QueryWatermarkOffsets", topic, int32(1), timeout).Return(int64(20), int64(50), nil)
+
+	lags, err = consumer.Lag(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []PartitionLag{
+		{Topic: topic, Partition: 0, Committed: 90, HighWatermark: 100, Lag: 10},
+		{Topic: topic, Partition: 1, Committed: -1, HighWatermark: 50, Lag: 30},
+	}, lags)
+	assert.Equal(t, "consumer-id", consumer.Name())
+
+	mockKafka.AssertExpectations(t)
+}
+
+func TestBatchConsumerLagError(t *testing.T) {
+	t.Parallel()
+
+	consumer, mockKafka := newTestBatchConsumer(t, defaultOpts...)
+
+	topic := "test-topic"
+
+	consumer.onPartitionsAssigned([]kafka.TopicPartition{{Topic: &topic, Partition: 0}})
+
+	mockKafka.On("Committed", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	_, err := consumer.Lag(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,242 @@
+package xkafka
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// LiftMode defines how the errors of a lifted middleware affect the batch.
+type LiftMode int
+
+// LiftMode enums.
+const (
+	// LiftFanOut runs the middleware for every message of the batch,
+	// and fails the batch with all the errors joined.
+	LiftFanOut LiftMode = iota
+	// LiftFailFast cancels the middleware for the remaining messages
+	// on the first error, and fails the batch with that error.
+	LiftFailFast
+)
+
+// Lift adapts a message middleware into a batch middleware, so that the
+// same middleware can be used with both Consumer and BatchConsumer.
+//
+// The middleware runs concurrently for every message of the batch.
+// Messages that reach the end of the middleware chain are gathered, and
+// handed to the next BatchHandler as a single batch once all the other
+// messages have either reached it too, or returned from the middleware.
+// The next BatchHandler runs with the context of the batch.
+//
+// Every round batch carries the ID and FlushReason of the batch. The batch
+// succeeds if any round succeeds, and is skipped if all rounds are skipped.
+//
+// The result of the batch is returned to the middleware of each message,
+// and messages not acknowledged by the BatchHandler are acknowledged with
+// the batch status, so that middlewares that retry, log or measure
+// messages observe the outcome of the batch. Messages dropped by the
+// middleware are not passed to the next BatchHandler.
+//
+// Lift starts a goroutine for every message of the batch, as all of them
+// must be in flight to be gathered. Its cost grows with the BatchSize.
+func Lift(mw Middlewarer, mode LiftMode) BatchMiddlewareFunc {
+	return func(next BatchHandler) BatchHandler {
+		return BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+			ctx, cancel := context.WithCancelCause(ctx)
+			defer cancel(nil)
+
+			l := &lifted{
+				ctx:    ctx,
+				next:   next,
+				id:     b.ID,
+				reason: b.FlushReason,
+				active: len(b.Messages),
+				order:  make(map[*Message]int),
+			}
+
+			for i, msg := range b.Messages {
+				l.order[msg] = i
+			}
+
+			handler := mw.Middleware(HandlerFunc(l.handle))
+			errs := make([]error, len(b.Messages))
+
+			var wg sync.WaitGroup
+
+			for i, msg := range b.Messages {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					errs[i] = handler.Handle(ctx, msg)
+					if errs[i] != nil && mode == LiftFailFast {
+						cancel(errs[i])
+					}
+
+					l.done()
+				}()
+			}
+
+			wg.Wait()
+
+			return l.ack(ctx, b, mode, errs)
+		})
+	}
+}
+
+// lifted gathers the messages of a batch that reach the end of
+// a message middleware chain, and hands them over in rounds.
+type lifted struct {
+	// ctx is the context of the batch, used for the next BatchHandler
+	ctx    context.Context
+	next   BatchHandler
+	id     string
+	reason FlushReason
+
+	mu      sync.Mutex
+	active  int
+	waiting []*liftedMessage
+	order   map[*Message]int
+	success bool
+}
+
+type liftedMessage struct {
+	msg    *Message
+	result chan error
+}
+
+func (l *lifted) handle(ctx context.Context, msg *Message) error {
+	lm := &liftedMessage{msg: msg, result: make(chan error, 1)}
+
+	l.mu.Lock()
+	l.waiting = append(l.waiting, lm)
+	round := l.takeRound()
+	l.mu.Unlock()
+
+	l.dispatch(round)
+
+	select {
+	case err := <-lm.result:
+		return err
+	case <-ctx.Done():
+		l.mu.Lock()
+		n := len(l.waiting)
+		l.waiting = slices.DeleteFunc(l.waiting, func(w *liftedMessage) bool { return w == lm })
+		taken := n == len(l.waiting)
+		l.mu.Unlock()
+
+		// the message is part of a round already handed over
+		if taken {
+			return <-lm.result
+		}
+
+		return context.Cause(ctx)
+	}
+}
+
+func (l *lifted) done() {
+	l.mu.Lock()
+	l.active--
+	round := l.takeRound()
+	l.mu.Unlock()
+
+	l.dispatch(round)
+}
+
+// takeRound returns the waiting messages once every active
+// message is waiting. It must be called with the lock held.
+func (l *lifted) takeRound() []*liftedMessage {
+	if len(l.waiting) == 0 || len(l.waiting) < l.active {
+		return nil
+	}
+
+	round := l.waiting
+	l.waiting = nil
+
+	return round
+}
+
+func (l *lifted) dispatch(round []*liftedMessage) {
+	if len(round) == 0 {
+		return
+	}
+
+	ctx := l.ctx
+
+	if ctx.Err() != nil {
+		for _, lm := range round {
+			lm.result <- context.Cause(ctx)
+		}
+
+		return
+	}
+
+	slices.SortFunc(round, func(a, b *liftedMessage) int {
+		return l.order[a.msg] - l.order[b.msg]
+	})
+
+	batch := NewBatch()
+	batch.ID = l.id
+	batch.FlushReason = l.reason
+	before := make([]Status, 0, len(round))
+
+	for _, lm := range round {
+		batch.Messages = append(batch.Messages, lm.msg)
+		before = append(before, lm.msg.Status)
+	}
+
+	err := l.next.HandleBatch(ctx, batch)
+	if err == nil && batch.Status == Fail {
+		err = batch.Err()
+	}
+
+	// rounds finish in any order, so the batch succeeds if any round did
+	if err == nil && batch.Status != Skip {
+		l.mu.Lock()
+		l.success = true
+		l.mu.Unlock()
+	}
+
+	for i, lm := range round {
+		// messages acknowledged by the handler keep their status
+		if lm.msg.Status == before[i] {
+			ackWithBatch(lm.msg, batch.Status, err)
+		}
+
+		lm.result <- err
+	}
+}
+
+func ackWithBatch(msg *Message, status Status, err error) {
+	switch {
+	case err != nil:
+		msg.AckFail(err)
+	case status == Skip:
+		msg.AckSkip()
+	default:
+		msg.AckSuccess()
+	}
+}
+
+func (l *lifted) ack(ctx context.Context, b *Batch, mode LiftMode, errs []error) error {
+	if mode == LiftFailFast && ctx.Err() != nil {
+		return b.AckFail(context.Cause(ctx))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return b.AckFail(err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.success {
+		b.AckSuccess()
+	} else {
+		b.AckSkip()
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+package xkafka
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLiftBatch(keys ...string) *Batch {
+	b := NewBatch()
+
+	for _, k := range keys {
+		b.Messages = append(b.Messages, &Message{Key: []byte(k)})
+	}
+
+	return b
+}
+
+func batchKeys(b *Batch) []string {
+	keys := make([]string, 0, len(b.Messages))
+	for _, m := range b.Messages {
+		keys = append(keys, string(m.Key))
+	}
+
+	return keys
+}
+
+func TestLiftFanOut(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		observed = map[string]Status{}
+	)
+
+	// drops messages with key "drop", and observes the status of the rest
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if string(msg.Key) == "drop" {
+				msg.AckSkip()
+
+				return nil
+			}
+
+			err := next.Handle(ctx, msg)
+
+			mu.Lock()
+			observed[string(msg.Key)] = msg.Status
+			mu.Unlock()
+
+			return err
+		})
+	})
+
+	var handled [][]string
+
+	handler := Lift(mw, LiftFanOut)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		handled = append(handled, batchKeys(b))
+		b.AckSuccess()
+
+		return nil
+	}))
+
+	b := newLiftBatch("a", "drop", "b", "c")
+
+	err := handler.HandleBatch(context.Background(), b)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, handled)
+	assert.Equal(t, Success, b.Status)
+	assert.Equal(t, map[string]Status{"a": Success, "b": Success, "c": Success}, observed)
+	assert.Equal(t, Skip, b.Messages[1].Status)
+}
+
+func TestLiftRetry(t *testing.T) {
+	t.Parallel()
+
+	// retries each message once
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if err := next.Handle(ctx, msg); err == nil {
+				return nil
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	})
+
+	var calls atomic.Int32
+
+	handler := Lift(mw, LiftFanOut)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		if calls.Add(1) == 1 {
+			return b.AckFail(assert.AnError)
+		}
+
+		assert.Len(t, b.Messages, 3)
+		b.AckSuccess()
+
+		return nil
+	}))
+
+	b := newLiftBatch("a", "b", "c")
+
+	err := handler.HandleBatch(context.Background(), b)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, calls.Load())
+	assert.Equal(t, Success, b.Status)
+
+	for _, msg := range b.Messages {
+		assert.Equal(t, Success, msg.Status)
+	}
+}
+
+func TestLiftFanOutErrors(t *testing.T) {
+	t.Parallel()
+
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if string(msg.Key) == "bad" {
+				return assert.AnError
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	})
+
+	var handled []string
+
+	handler := Lift(mw, LiftFanOut)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		handled = batchKeys(b)
+
+		return nil
+	}))
+
+	b := newLiftBatch("a", "bad", "b")
+
+	err := handler.HandleBatch(context.Background(), b)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Fail, b.Status)
+	assert.Equal(t, []string{"a", "b"}, handled)
+}
+
+func TestLiftFailFast(t *testing.T) {
+	t.Parallel()
+
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if string(msg.Key) == "bad" {
+				return assert.AnError
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	})
+
+	called := false
+
+	handler := Lift(mw, LiftFailFast)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		called = true
+
+		return nil
+	}))
+
+	b := newLiftBatch("a", "bad", "b")
+
+	err := handler.HandleBatch(context.Background(), b)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Fail, b.Status)
+	assert.False(t, called)
+}
+
+func TestLiftSkip(t *testing.T) {
+	t.Parallel()
+
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			msg.AckSkip()
+
+			return nil
+		})
+	})
+
+	handler := Lift(mw, LiftFanOut)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		t.Fatal("unexpected call")
+
+		return nil
+	}))
+
+	b := newLiftBatch("a", "b")
+
+	require.NoError(t, handler.HandleBatch(context.Background(), b))
+	assert.Equal(t, Skip, b.Status)
+}
+
+type liftCtxKey struct{}
+
+func TestLiftBatchContext(t *testing.T) {
+	t.Parallel()
+
+	// sets a value of its own on the context of every message
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			return next.Handle(context.WithValue(ctx, liftCtxKey{}, string(msg.Key)), msg)
+		})
+	})
+
+	var values []any
+
+	handler := Lift(mw, LiftFanOut)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		values = append(values, ctx.Value(liftCtxKey{}))
+		b.AckSuccess()
+
+		return nil
+	}))
+
+	ctx := context.WithValue(context.Background(), liftCtxKey{}, "batch")
+
+	err := handler.HandleBatch(ctx, newLiftBatch("a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []any{"batch"}, values)
+}
+
+func TestLiftRounds(t *testing.T) {
+	t.Parallel()
+
+	// hands message "a" over twice, so that it is alone in the second round
+	mw := MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if err := next.Handle(ctx, msg); err != nil || string(msg.Key) != "a" {
+				return err
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	})
+
+	var rounds []*Batch
+
+	handler := Lift(mw, LiftFanOut)(BatchHandlerFunc(func(ctx context.Context, b *Batch) error {
+		rounds = append(rounds, b)
+
+		if len(b.Messages) == 1 {
+			b.AckSkip()
+
+			return nil
+		}
+
+		b.AckSuccess()
+
+		return nil
+	}))
+
+	b := newLiftBatch("a", "b")
+	b.FlushReason = FlushTimeout
+
+	require.NoError(t, handler.HandleBatch(context.Background(), b))
+	require.Len(t, rounds, 2)
+
+	for _, round := range rounds {
+		assert.Equal(t, b.ID, round.ID)
+		assert.Equal(t, FlushTimeout, round.FlushReason)
+	}
+
+	// a skipped round does not override a successful one
+	assert.Equal(t, Success, b.Status)
+}
@@ -7,6 +7,10 @@ import (
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
+// HeaderMessageID is the header that carries Message.ID.
+// It is set by the Producer, and restored by the consumers.
+const HeaderMessageID = "xkafka-message-id"
+
 // AckFunc are callback funtions triggered for every ack.
 type AckFunc func(m *Message)
 
@@ -44,11 +48,15 @@ type Message struct {
 	ackCallbacks []AckFunc         `json:"-"`
 	mutex        sync.Mutex        `json:"-"`
 	err          error             `json:"-"`
+	enqueuedAt   time.Time         `json:"-"`
 }
 
 // newMessage creates a new message from a kafka message.
 func newMessage(group string, raw *kafka.Message) *Message {
+	headers := mapHeaders(raw.Headers)
+
 	return &Message{
+		ID:        string(headers[HeaderMessageID]),
 		Topic:     *raw.TopicPartition.Topic,
 		Partition: raw.TopicPartition.Partition,
 		Group:     group,
@@ -57,7 +65,7 @@ func newMessage(group string, raw *kafka.Message) *Message {
 		Timestamp: raw.Timestamp,
 		Status:    Unassigned,
 		Offset:    int64(raw.TopicPartition.Offset),
-		headers:   mapHeaders(raw.Headers),
+		headers:   headers,
 	}
 }
 
@@ -124,6 +132,10 @@ func (m *Message) Err() error {
 
 // SetHeader stores the key and value of the header field of the message.
 func (m *Message) SetHeader(key string, value []byte) {
+	if m.headers == nil {
+		m.headers = make(map[string][]byte)
+	}
+
 	m.headers[key] = value
 }
 
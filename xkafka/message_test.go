@@ -51,6 +51,22 @@ func TestNewMessage(t *testing.T) {
 	assert.Equal(t, expectMsg.headers, m.headers)
 }
 
+func TestNewMessageRestoresID(t *testing.T) {
+	km := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: 1,
+		},
+		Value: []byte("value"),
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte("message-id")},
+		},
+	}
+
+	m := newMessage("consumer-group-1", km)
+	assert.Equal(t, "message-id", m.ID)
+}
+
 func TestMessageAsKafkaMessage(t *testing.T) {
 	expectKafka := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
@@ -269,6 +285,13 @@ func TestHeaders(t *testing.T) {
 	m.SetHeader("foo", []byte("bar"))
 	assert.Equal(t, m.Headers(), map[string][]byte{"foo": []byte("bar")})
 	assert.Equal(t, m.Header("foo"), []byte("bar"))
+
+	t.Run("without headers", func(t *testing.T) {
+		m := &Message{}
+
+		m.SetHeader("foo", []byte("bar"))
+		assert.Equal(t, []byte("bar"), m.Header("foo"))
+	})
 }
 
 func TestStatus_String(t *testing.T) {
@@ -0,0 +1,356 @@
+// Package circuitbreaker provides a circuit breaker middleware that stops
+// calling a failing handler until its dependencies recover.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+var (
+	// ErrOpen is returned when the circuit breaker is open and
+	// the message is not handed over to the handler. It is returned
+	// only when PauseOnOpen is disabled.
+	//
+	// WARNING: The rejected message is marked as failed, but the consumer
+	// keeps committing the offsets of the following messages. Unless the
+	// xkafka.ErrorHandler stops on ErrOpen, the rejected messages are lost.
+	ErrOpen = errors.New("[xkafka/circuitbreaker] circuit breaker is open")
+)
+
+// State is the state of the circuit breaker.
+type State int
+
+// State enums.
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+// String returns a string state value.
+func (s State) String() string {
+	return [...]string{"CLOSED", "OPEN", "HALF_OPEN"}[s]
+}
+
+// Option configures the circuit breaker.
+type Option interface {
+	apply(*config)
+}
+
+// FailureThreshold sets the number of consecutive failures
+// after which the circuit breaker opens.
+type FailureThreshold int
+
+func (f FailureThreshold) apply(c *config) { c.failureThreshold = int(f) }
+
+// SuccessThreshold sets the number of consecutive successes in
+// half-open state after which the circuit breaker closes.
+type SuccessThreshold int
+
+func (s SuccessThreshold) apply(c *config) { c.successThreshold = int(s) }
+
+// OpenTimeout sets the duration the circuit breaker stays open
+// before allowing trial messages in half-open state.
+type OpenTimeout time.Duration
+
+func (o OpenTimeout) apply(c *config) { c.openTimeout = time.Duration(o) }
+
+// HalfOpenMaxMessages sets the number of trial messages allowed
+// concurrently in half-open state.
+type HalfOpenMaxMessages int
+
+func (h HalfOpenMaxMessages) apply(c *config) { c.halfOpenMax = int(h) }
+
+// PauseOnOpen blocks the handler while the circuit breaker is open,
+// instead of failing fast with ErrOpen. This pauses consumption until
+// the circuit breaker allows a trial message. Enabled by default.
+//
+// WARNING: The OpenTimeout should be less than `max.poll.interval.ms`
+// kafka consumer config, otherwise the consumer is removed from the group.
+type PauseOnOpen bool
+
+func (p PauseOnOpen) apply(c *config) { c.pause = bool(p) }
+
+// OnStateChange is called after every state transition.
+// It can be used to log or export the state of the circuit breaker.
+type OnStateChange func(name string, from, to State)
+
+func (fn OnStateChange) apply(c *config) { c.onStateChange = fn }
+
+type config struct {
+	failureThreshold int
+	successThreshold int
+	openTimeout      time.Duration
+	halfOpenMax      int
+	pause            bool
+	onStateChange    OnStateChange
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		failureThreshold: 5,
+		successThreshold: 1,
+		openTimeout:      30 * time.Second,
+		halfOpenMax:      1,
+		pause:            true,
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// halfOpenPollInterval is the interval at which a paused handler checks
+// for a free trial slot while the circuit breaker is half-open.
+const halfOpenPollInterval = 100 * time.Millisecond
+
+// Breaker is a circuit breaker with closed, open and half-open states.
+//
+// In closed state, messages are handed over to the handler, and consecutive
+// failures are counted. After FailureThreshold failures, the breaker opens.
+// In open state, messages wait, or fail fast with ErrOpen if PauseOnOpen is disabled.
+// After OpenTimeout, the breaker becomes half-open and allows a few trial
+// messages. A failed trial opens the breaker again, while SuccessThreshold
+// successful trials close it.
+//
+// Breaker implements the xpod.Checker interface, and reports an error
+// while the circuit breaker is open. The numeric value of State can be
+// exported as a gauge, for example with prometheus.NewGaugeFunc.
+type Breaker struct {
+	name string
+	cfg  *config
+
+	mu           sync.Mutex
+	state        State
+	generation   uint64
+	failures     int
+	successes    int
+	halfOpenUsed int
+	openedAt     time.Time
+}
+
+// New creates a new Breaker.
+// Default values:
+// - FailureThreshold: 5
+// - SuccessThreshold: 1
+// - OpenTimeout: 30 seconds
+// - HalfOpenMaxMessages: 1
+// - PauseOnOpen: true
+func New(name string, opts ...Option) *Breaker {
+	return &Breaker{
+		name: name,
+		cfg:  newConfig(opts...),
+	}
+}
+
+// Name returns the name of the circuit breaker.
+func (b *Breaker) Name() string { return b.name }
+
+// State returns the current state of the circuit breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && time.Since(b.openedAt) >= b.cfg.openTimeout {
+		return HalfOpen
+	}
+
+	return b.state
+}
+
+// Check returns ErrOpen while the circuit breaker is open.
+func (b *Breaker) Check(_ *http.Request) error {
+	if b.State() == Open {
+		return ErrOpen
+	}
+
+	return nil
+}
+
+// Middleware returns a middleware that guards xkafka.Consumer handlers.
+// A message counts as a failure if the handler returns an error,
+// marks the message as failed, or panics.
+func (b *Breaker) Middleware() xkafka.MiddlewareFunc {
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			gen, err := b.acquire(ctx)
+			if err != nil {
+				msg.AckFail(err)
+
+				return err
+			}
+
+			// a panicking handler counts as a failure
+			failed := true
+			defer func() { b.release(gen, failed) }()
+
+			err = next.Handle(ctx, msg)
+			failed = err != nil || msg.Status == xkafka.Fail
+
+			return err
+		})
+	}
+}
+
+// BatchMiddleware returns a middleware that guards xkafka.BatchConsumer handlers.
+// A batch counts as a single failure or success, and a panic as a failure.
+func (b *Breaker) BatchMiddleware() xkafka.BatchMiddlewareFunc {
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+			gen, err := b.acquire(ctx)
+			if err != nil {
+				return batch.AckFail(err)
+			}
+
+			// a panicking handler counts as a failure
+			failed := true
+			defer func() { b.release(gen, failed) }()
+
+			err = next.HandleBatch(ctx, batch)
+			failed = err != nil || batch.Status == xkafka.Fail
+
+			return err
+		})
+	}
+}
+
+// acquire waits for, or rejects, permission to call the handler.
+// It returns the generation of the state in which permission was granted.
+func (b *Breaker) acquire(ctx context.Context) (uint64, error) {
+	for {
+		gen, wait, err := b.allow(time.Now())
+		if err == nil {
+			return gen, nil
+		}
+
+		if !b.cfg.pause {
+			return 0, err
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (b *Breaker) allow(now time.Time) (uint64, time.Duration, error) {
+	b.mu.Lock()
+
+	var changed func()
+
+	if b.state == Open {
+		remaining := b.cfg.openTimeout - now.Sub(b.openedAt)
+		if remaining > 0 {
+			b.mu.Unlock()
+
+			return 0, remaining, ErrOpen
+		}
+
+		changed = b.setState(HalfOpen)
+	}
+
+	if b.state == HalfOpen {
+		if b.halfOpenUsed >= b.cfg.halfOpenMax {
+			b.mu.Unlock()
+
+			return 0, halfOpenPollInterval, ErrOpen
+		}
+
+		b.halfOpenUsed++
+	}
+
+	gen := b.generation
+
+	b.mu.Unlock()
+
+	if changed != nil {
+		changed()
+	}
+
+	return gen, 0, nil
+}
+
+func (b *Breaker) release(gen uint64, failed bool) {
+	b.mu.Lock()
+
+	// ignore results of messages acquired before the last state change
+	if gen != b.generation {
+		b.mu.Unlock()
+
+		return
+	}
+
+	var changed func()
+
+	switch b.state {
+	case Closed:
+		if !failed {
+			b.failures = 0
+
+			break
+		}
+
+		b.failures++
+
+		if b.failures >= b.cfg.failureThreshold {
+			changed = b.setState(Open)
+		}
+
+	case HalfOpen:
+		if failed {
+			changed = b.setState(Open)
+
+			break
+		}
+
+		b.successes++
+		b.halfOpenUsed--
+
+		if b.successes >= b.cfg.successThreshold {
+			changed = b.setState(Closed)
+		}
+	}
+
+	b.mu.Unlock()
+
+	if changed != nil {
+		changed()
+	}
+}
+
+// setState transitions to the given state and resets the counters.
+// It must be called with the lock held, and returns a func that notifies
+// the OnStateChange callback, to be called after the lock is released.
+func (b *Breaker) setState(to State) func() {
+	from := b.state
+
+	b.state = to
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.halfOpenUsed = 0
+
+	if to == Open {
+		b.openedAt = time.Now()
+	}
+
+	if b.cfg.onStateChange == nil {
+		return nil
+	}
+
+	return func() { b.cfg.onStateChange(b.name, from, to) }
+}
@@ -0,0 +1,234 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+func TestBreaker_OpensAfterFailures(t *testing.T) {
+	calls := 0
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		calls++
+
+		return assert.AnError
+	})
+
+	b := New("test", FailureThreshold(3), OpenTimeout(time.Minute), PauseOnOpen(false))
+	h := b.Middleware()(handler)
+
+	for range 3 {
+		err := h.Handle(context.TODO(), &xkafka.Message{})
+		assert.ErrorIs(t, err, assert.AnError)
+	}
+
+	assert.Equal(t, Open, b.State())
+	assert.ErrorIs(t, b.Check(nil), ErrOpen)
+
+	msg := &xkafka.Message{}
+	err := h.Handle(context.TODO(), msg)
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.Equal(t, xkafka.Fail, msg.Status)
+	assert.Equal(t, 3, calls)
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	fail := true
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		if fail {
+			m.AckFail(assert.AnError)
+
+			return nil
+		}
+
+		m.AckSuccess()
+
+		return nil
+	})
+
+	b := New("test", FailureThreshold(2))
+	h := b.Middleware()(handler)
+
+	assert.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+
+	fail = false
+	assert.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+
+	fail = true
+	assert.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+
+	assert.Equal(t, Closed, b.State())
+	assert.NoError(t, b.Check(nil))
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	fail := true
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		if fail {
+			return assert.AnError
+		}
+
+		return nil
+	})
+
+	var transitions []State
+
+	b := New("test",
+		FailureThreshold(1),
+		SuccessThreshold(2),
+		OpenTimeout(20*time.Millisecond),
+		OnStateChange(func(name string, from, to State) {
+			assert.Equal(t, "test", name)
+
+			transitions = append(transitions, to)
+		}),
+	)
+	h := b.Middleware()(handler)
+
+	err := h.Handle(context.TODO(), &xkafka.Message{})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Open, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, HalfOpen, b.State())
+
+	// failed trial re-opens the breaker
+	err = h.Handle(context.TODO(), &xkafka.Message{})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Open, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+
+	fail = false
+
+	assert.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+	assert.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+	assert.Equal(t, Closed, b.State())
+
+	assert.Equal(t, []State{Open, HalfOpen, Open, HalfOpen, Closed}, transitions)
+}
+
+// PauseOnOpen is enabled by default, so rejected messages are not lost.
+func TestBreaker_PauseOnOpen(t *testing.T) {
+	fail := true
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		if fail {
+			return assert.AnError
+		}
+
+		return nil
+	})
+
+	b := New("test",
+		FailureThreshold(1),
+		OpenTimeout(50*time.Millisecond),
+	)
+	h := b.Middleware()(handler)
+
+	err := h.Handle(context.TODO(), &xkafka.Message{})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	fail = false
+	start := time.Now()
+
+	err = h.Handle(context.TODO(), &xkafka.Message{})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	assert.Equal(t, Closed, b.State())
+
+	t.Run("context cancelled", func(t *testing.T) {
+		fail = true
+
+		err := h.Handle(context.TODO(), &xkafka.Message{})
+		assert.ErrorIs(t, err, assert.AnError)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err = h.Handle(ctx, &xkafka.Message{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestBreaker_BatchMiddleware(t *testing.T) {
+	b := New("test", FailureThreshold(1), OpenTimeout(time.Minute), PauseOnOpen(false))
+
+	handler := b.BatchMiddleware()(xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+		return batch.AckFail(assert.AnError)
+	}))
+
+	err := handler.HandleBatch(context.TODO(), xkafka.NewBatch())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Open, b.State())
+
+	batch := xkafka.NewBatch()
+	err = handler.HandleBatch(context.TODO(), batch)
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.Equal(t, xkafka.Fail, batch.Status)
+}
+
+func TestBreaker_HalfOpenPanic(t *testing.T) {
+	panics := true
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		if panics {
+			panic("boom")
+		}
+
+		return nil
+	})
+
+	b := New("test", FailureThreshold(1), OpenTimeout(20*time.Millisecond), PauseOnOpen(false))
+	h := b.Middleware()(handler)
+
+	handle := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("recovered: %v", r)
+			}
+		}()
+
+		return h.Handle(context.TODO(), &xkafka.Message{})
+	}
+
+	assert.ErrorContains(t, handle(), "recovered: boom")
+	assert.Equal(t, Open, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, HalfOpen, b.State())
+
+	// the panicking trial releases its slot and re-opens the breaker
+	assert.ErrorContains(t, handle(), "recovered: boom")
+	assert.Equal(t, Open, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+
+	panics = false
+
+	assert.NoError(t, handle())
+	assert.Equal(t, Closed, b.State())
+
+	t.Run("batch", func(t *testing.T) {
+		b := New("test", FailureThreshold(1), OpenTimeout(20*time.Millisecond), PauseOnOpen(false))
+		h := b.BatchMiddleware()(xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+			panic("boom")
+		}))
+
+		for range 2 {
+			assert.Panics(t, func() { _ = h.HandleBatch(context.TODO(), xkafka.NewBatch()) })
+			assert.Equal(t, Open, b.State())
+
+			time.Sleep(30 * time.Millisecond)
+		}
+	})
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "CLOSED", Closed.String())
+	assert.Equal(t, "OPEN", Open.String())
+	assert.Equal(t, "HALF_OPEN", HalfOpen.String())
+}
@@ -0,0 +1,48 @@
+package circuitbreaker_test
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xkafka/middleware/circuitbreaker"
+)
+
+func Example() {
+	handler := func(ctx context.Context, m *xkafka.Message) error {
+		// call a downstream dependency
+		return nil
+	}
+
+	consumer, err := xkafka.NewConsumer(
+		"circuitbreaker-consumer",
+		xkafka.HandlerFunc(handler),
+		xkafka.Brokers{"localhost:9092"},
+		xkafka.Topics{"test-topic"},
+		xkafka.ErrorHandler(func(err error) error {
+			slog.Error(err.Error())
+
+			return nil
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	breaker := circuitbreaker.New(
+		"downstream",
+		circuitbreaker.FailureThreshold(5),         // open after 5 consecutive failures
+		circuitbreaker.OpenTimeout(30*time.Second), // try again after 30 seconds
+		circuitbreaker.OnStateChange(func(name string, from, to circuitbreaker.State) {
+			slog.Info("circuit breaker state changed", "name", name, "from", from, "to", to)
+		}),
+	)
+
+	consumer.Use(breaker.Middleware())
+
+	// breaker can be added to xpod.Options.ReadyCheckers
+	// to report the consumer as not ready while open.
+
+	// ... run consumer
+}
@@ -0,0 +1,159 @@
+// Package claimcheck provides producer and consumer middlewares that implement
+// the claim-check pattern for payloads larger than `message.max.bytes`.
+//
+// The producer middleware writes payloads above a threshold to a BlobStore,
+// and replaces the message value with a reference in the HeaderClaimCheck header.
+// The consumer middleware reads the payload back from the BlobStore before
+// handing over the message to the next handler.
+package claimcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+// HeaderClaimCheck is the header that carries the reference
+// of the payload in the BlobStore.
+const HeaderClaimCheck = "xkafka-claim-check"
+
+var (
+	// ErrNotFound is returned by a BlobStore when the reference does not exist.
+	ErrNotFound = errors.New("[xkafka/claimcheck] payload not found")
+)
+
+// BlobStore stores message payloads outside of Kafka.
+type BlobStore interface {
+	// Put stores the payload under the given reference.
+	Put(ctx context.Context, ref string, data []byte) error
+	// Get returns the payload stored under the given reference.
+	Get(ctx context.Context, ref string) ([]byte, error)
+}
+
+// Option configures the claim-check middleware.
+type Option interface {
+	apply(*config)
+}
+
+// Threshold sets the payload size in bytes above which the payload
+// is written to the BlobStore.
+type Threshold int
+
+func (t Threshold) apply(c *config) { c.threshold = int(t) }
+
+type config struct {
+	threshold int
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		// below the 1MB default `message.max.bytes`,
+		// leaving room for the key and headers
+		threshold: 900 * 1024,
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// ProducerMiddleware is a middleware for xkafka.Producer that writes
+// payloads larger than the threshold to the BlobStore.
+// Default values:
+// - Threshold: 900KB
+func ProducerMiddleware(store BlobStore, opts ...Option) xkafka.MiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			if len(msg.Value) <= cfg.threshold {
+				return next.Handle(ctx, msg)
+			}
+
+			ref, err := newRef(msg)
+			if err != nil {
+				return err
+			}
+
+			if err := store.Put(ctx, ref, msg.Value); err != nil {
+				return fmt.Errorf("%w: %w", xkafka.ErrRetryable, err)
+			}
+
+			msg.Value = nil
+			msg.SetHeader(HeaderClaimCheck, []byte(ref))
+
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
+// ConsumerMiddleware is a middleware for xkafka.Consumer that reads
+// the payloads of claim-check messages from the BlobStore.
+func ConsumerMiddleware(store BlobStore) xkafka.MiddlewareFunc {
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			if err := rehydrate(ctx, store, msg); err != nil {
+				msg.AckFail(err)
+
+				return err
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
+// BatchConsumerMiddleware is a middleware for xkafka.BatchConsumer that reads
+// the payloads of claim-check messages from the BlobStore.
+func BatchConsumerMiddleware(store BlobStore) xkafka.BatchMiddlewareFunc {
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+			for _, msg := range batch.Messages {
+				if err := rehydrate(ctx, store, msg); err != nil {
+					return batch.AckFail(err)
+				}
+			}
+
+			return next.HandleBatch(ctx, batch)
+		})
+	}
+}
+
+func rehydrate(ctx context.Context, store BlobStore, msg *xkafka.Message) error {
+	ref := msg.Header(HeaderClaimCheck)
+	if len(ref) == 0 {
+		return nil
+	}
+
+	data, err := store.Get(ctx, string(ref))
+	if err != nil {
+		return err
+	}
+
+	msg.Value = data
+
+	return nil
+}
+
+// newRef returns a reference scoped by topic, using the message ID
+// when available, or a random ID otherwise.
+func newRef(msg *xkafka.Message) (string, error) {
+	id := msg.ID
+
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+
+		id = hex.EncodeToString(b)
+	}
+
+	return msg.Topic + "/" + id, nil
+}
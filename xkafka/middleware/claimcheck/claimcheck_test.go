@@ -0,0 +1,174 @@
+package claimcheck
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+type errStore struct{}
+
+func (errStore) Put(context.Context, string, []byte) error { return assert.AnError }
+
+func (errStore) Get(context.Context, string) ([]byte, error) { return nil, assert.AnError }
+
+func TestClaimCheck_RoundTrip(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	payload := bytes.Repeat([]byte("x"), 64)
+
+	var published *xkafka.Message
+
+	publish := ProducerMiddleware(store, Threshold(32))(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		published = m
+
+		return nil
+	}))
+
+	msg := &xkafka.Message{ID: "msg-1", Topic: "test-topic", Value: payload}
+
+	require.NoError(t, publish.Handle(context.TODO(), msg))
+	assert.Empty(t, published.Value)
+	assert.Equal(t, []byte("test-topic/msg-1"), published.Header(HeaderClaimCheck))
+
+	var consumed []byte
+
+	consume := ConsumerMiddleware(store)(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		consumed = m.Value
+
+		return nil
+	}))
+
+	require.NoError(t, consume.Handle(context.TODO(), published))
+	assert.Equal(t, payload, consumed)
+}
+
+func TestProducerMiddleware_BelowThreshold(t *testing.T) {
+	publish := ProducerMiddleware(errStore{}, Threshold(32))(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	}))
+
+	msg := &xkafka.Message{Topic: "test-topic", Value: []byte("small")}
+
+	require.NoError(t, publish.Handle(context.TODO(), msg))
+	assert.Equal(t, []byte("small"), msg.Value)
+	assert.Nil(t, msg.Header(HeaderClaimCheck))
+}
+
+func TestProducerMiddleware_RandomRef(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	publish := ProducerMiddleware(store, Threshold(1))(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	}))
+
+	msg := &xkafka.Message{Topic: "test-topic", Value: []byte("payload")}
+
+	require.NoError(t, publish.Handle(context.TODO(), msg))
+
+	data, err := store.Get(context.TODO(), string(msg.Header(HeaderClaimCheck)))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), data)
+}
+
+func TestProducerMiddleware_StoreError(t *testing.T) {
+	publish := ProducerMiddleware(errStore{}, Threshold(1))(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	}))
+
+	err := publish.Handle(context.TODO(), &xkafka.Message{Topic: "test-topic", Value: []byte("payload")})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorIs(t, err, xkafka.ErrRetryable)
+}
+
+func TestConsumerMiddleware_StoreError(t *testing.T) {
+	consume := ConsumerMiddleware(errStore{})(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	}))
+
+	msg := &xkafka.Message{}
+	msg.SetHeader(HeaderClaimCheck, []byte("test-topic/msg-1"))
+
+	err := consume.Handle(context.TODO(), msg)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, xkafka.Fail, msg.Status)
+}
+
+func TestBatchConsumerMiddleware(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	require.NoError(t, store.Put(context.TODO(), "test-topic/msg-1", []byte("payload")))
+
+	large := &xkafka.Message{}
+	large.SetHeader(HeaderClaimCheck, []byte("test-topic/msg-1"))
+
+	batch := xkafka.NewBatch()
+	batch.Messages = []*xkafka.Message{large, {Value: []byte("small")}}
+
+	handler := BatchConsumerMiddleware(store)(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+		b.AckSuccess()
+
+		return nil
+	}))
+
+	require.NoError(t, handler.HandleBatch(context.TODO(), batch))
+	assert.Equal(t, []byte("payload"), batch.Messages[0].Value)
+	assert.Equal(t, []byte("small"), batch.Messages[1].Value)
+
+	t.Run("missing payload", func(t *testing.T) {
+		missing := &xkafka.Message{}
+		missing.SetHeader(HeaderClaimCheck, []byte("test-topic/msg-2"))
+
+		batch := xkafka.NewBatch()
+		batch.Messages = []*xkafka.Message{missing}
+
+		err := handler.HandleBatch(context.TODO(), batch)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, xkafka.Fail, batch.Status)
+	})
+}
+
+func TestFileStore_InvalidRef(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	err := store.Put(context.TODO(), "../escape", []byte("payload"))
+	assert.Error(t, err)
+
+	_, err = store.Get(context.TODO(), "/etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestFileStore_ConcurrentPut(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := store.Put(context.TODO(), "test-topic/msg-1", bytes.Repeat([]byte{byte('a' + i)}, 1024))
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	data, err := store.Get(context.TODO(), "test-topic/msg-1")
+	require.NoError(t, err)
+	assert.Len(t, data, 1024)
+	assert.Equal(t, bytes.Repeat(data[:1], 1024), data)
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir + "/test-topic")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
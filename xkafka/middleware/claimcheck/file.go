@@ -0,0 +1,88 @@
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileStore is a BlobStore that keeps payloads as files in a directory.
+// The directory can be a shared volume, for producers and consumers
+// running on different hosts.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a new FileStore rooted at dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Put writes the payload to a file named after the reference.
+func (s *FileStore) Put(_ context.Context, ref string, data []byte) error {
+	path, err := s.path(ref)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that readers
+	// never observe a partially written payload
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := writeTemp(tmp, data); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return nil
+}
+
+func writeTemp(f *os.File, data []byte) error {
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// Get reads the payload from the file named after the reference.
+func (s *FileStore) Get(_ context.Context, ref string) ([]byte, error) {
+	path, err := s.path(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+
+	return data, err
+}
+
+func (s *FileStore) path(ref string) (string, error) {
+	if !filepath.IsLocal(ref) {
+		return "", fmt.Errorf("[xkafka/claimcheck] invalid reference: %q", ref)
+	}
+
+	return filepath.Join(s.dir, ref), nil
+}
@@ -0,0 +1,225 @@
+// Package concurrency provides middlewares that shape the number of
+// messages processed concurrently.
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+// Option configures the AdaptiveLimiter.
+type Option interface {
+	apply(*config)
+}
+
+// InitialLimit sets the concurrency limit to start with.
+type InitialLimit int
+
+func (i InitialLimit) apply(c *config) { c.initialLimit = int(i) }
+
+// MinLimit sets the lower bound of the concurrency limit.
+// Values below 1 are raised to 1.
+type MinLimit int
+
+func (m MinLimit) apply(c *config) { c.minLimit = int(m) }
+
+// MaxLimit sets the upper bound of the concurrency limit.
+type MaxLimit int
+
+func (m MaxLimit) apply(c *config) { c.maxLimit = int(m) }
+
+// LatencyThreshold sets the handler latency above which
+// the concurrency limit is decreased.
+type LatencyThreshold time.Duration
+
+func (l LatencyThreshold) apply(c *config) { c.latencyThreshold = time.Duration(l) }
+
+// ErrorRateThreshold sets the ratio of failed messages, between 0 and 1,
+// above which the concurrency limit is decreased.
+type ErrorRateThreshold float64
+
+func (e ErrorRateThreshold) apply(c *config) { c.errorRateThreshold = float64(e) }
+
+// BackoffRatio sets the multiplier applied to the concurrency limit
+// when it is decreased. It must be between 0 and 1, exclusive.
+type BackoffRatio float64
+
+func (b BackoffRatio) apply(c *config) { c.backoffRatio = float64(b) }
+
+type config struct {
+	initialLimit       int
+	minLimit           int
+	maxLimit           int
+	latencyThreshold   time.Duration
+	errorRateThreshold float64
+	backoffRatio       float64
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		initialLimit:       10,
+		minLimit:           1,
+		maxLimit:           100,
+		latencyThreshold:   time.Second,
+		errorRateThreshold: 0.1,
+		backoffRatio:       0.9,
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	if c.backoffRatio <= 0 || c.backoffRatio >= 1 {
+		panic(fmt.Sprintf("[xkafka/concurrency] BackoffRatio must be between 0 and 1, got %v", c.backoffRatio))
+	}
+
+	// a limit of 0 never admits a message, and never recovers
+	c.minLimit = max(c.minLimit, 1)
+	c.maxLimit = max(c.maxLimit, c.minLimit)
+	c.initialLimit = min(max(c.initialLimit, c.minLimit), c.maxLimit)
+
+	return c
+}
+
+// errorRateSmoothing is the weight of the latest sample
+// in the moving average of the error rate.
+const errorRateSmoothing = 0.1
+
+// AdaptiveLimiter limits the number of in-flight messages, adjusting the
+// limit to the observed handler latency and error rate. The limit grows
+// additively while the handler is healthy, and shrinks multiplicatively
+// when latency or error rate crosses the configured thresholds.
+type AdaptiveLimiter struct {
+	cfg       *config
+	mu        sync.Mutex
+	limit     float64
+	inflight  int
+	errorRate float64
+	changed   chan struct{}
+}
+
+// NewAdaptiveLimiter creates a new AdaptiveLimiter. MinLimit is raised to 1,
+// and InitialLimit is kept between MinLimit and MaxLimit. It panics if
+// BackoffRatio is not between 0 and 1.
+// Default values:
+// - InitialLimit: 10
+// - MinLimit: 1
+// - MaxLimit: 100
+// - LatencyThreshold: 1 second
+// - ErrorRateThreshold: 0.1
+// - BackoffRatio: 0.9
+func NewAdaptiveLimiter(opts ...Option) *AdaptiveLimiter {
+	cfg := newConfig(opts...)
+
+	return &AdaptiveLimiter{
+		cfg:     cfg,
+		limit:   float64(cfg.initialLimit),
+		changed: make(chan struct{}),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// Inflight returns the number of messages currently being processed.
+func (l *AdaptiveLimiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inflight
+}
+
+// Middleware returns a middleware that limits the concurrency of xkafka.Consumer.
+// It is effective only when the consumer runs with xkafka.Concurrency > 1.
+func (l *AdaptiveLimiter) Middleware() xkafka.MiddlewareFunc {
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			if err := l.acquire(ctx); err != nil {
+				return err
+			}
+
+			start := time.Now()
+
+			err := next.Handle(ctx, msg)
+
+			l.release(time.Since(start), err != nil || msg.Status == xkafka.Fail)
+
+			return err
+		})
+	}
+}
+
+// BatchMiddleware returns a middleware that limits the concurrency of
+// xkafka.BatchConsumer. Each batch counts as one in-flight unit.
+func (l *AdaptiveLimiter) BatchMiddleware() xkafka.BatchMiddlewareFunc {
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+			if err := l.acquire(ctx); err != nil {
+				return err
+			}
+
+			start := time.Now()
+
+			err := next.HandleBatch(ctx, batch)
+
+			l.release(time.Since(start), err != nil || batch.Status == xkafka.Fail)
+
+			return err
+		})
+	}
+}
+
+func (l *AdaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+
+		if l.inflight < int(l.limit) {
+			l.inflight++
+			l.mu.Unlock()
+
+			return nil
+		}
+
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+
+	sample := 0.0
+	if failed {
+		sample = 1
+	}
+
+	l.errorRate = errorRateSmoothing*sample + (1-errorRateSmoothing)*l.errorRate
+
+	if latency > l.cfg.latencyThreshold || l.errorRate > l.cfg.errorRateThreshold {
+		l.limit = max(l.limit*l.cfg.backoffRatio, float64(l.cfg.minLimit))
+	} else {
+		l.limit = min(l.limit+1/l.limit, float64(l.cfg.maxLimit))
+	}
+
+	// wake up all waiters to re-check the limit
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,183 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+func TestAdaptiveLimiter_LimitsInflight(t *testing.T) {
+	l := NewAdaptiveLimiter(InitialLimit(2), MaxLimit(2))
+
+	var inflight, peak atomic.Int32
+
+	handler := l.Middleware()(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		return nil
+	}))
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := handler.Handle(context.TODO(), &xkafka.Message{})
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+	assert.Equal(t, 0, l.Inflight())
+}
+
+func TestAdaptiveLimiter_ShrinksOnErrors(t *testing.T) {
+	l := NewAdaptiveLimiter(InitialLimit(10), MinLimit(2), BackoffRatio(0.5))
+
+	handler := l.Middleware()(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		m.AckFail(assert.AnError)
+
+		return assert.AnError
+	}))
+
+	for range 10 {
+		err := handler.Handle(context.TODO(), &xkafka.Message{})
+		assert.ErrorIs(t, err, assert.AnError)
+	}
+
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestAdaptiveLimiter_ShrinksOnLatency(t *testing.T) {
+	l := NewAdaptiveLimiter(InitialLimit(10), LatencyThreshold(time.Millisecond))
+
+	handler := l.Middleware()(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		time.Sleep(5 * time.Millisecond)
+
+		return nil
+	}))
+
+	err := handler.Handle(context.TODO(), &xkafka.Message{})
+	assert.NoError(t, err)
+	assert.Equal(t, 9, l.Limit())
+}
+
+func TestAdaptiveLimiter_GrowsWhenHealthy(t *testing.T) {
+	l := NewAdaptiveLimiter(InitialLimit(1), MaxLimit(3))
+
+	handler := l.Middleware()(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		m.AckSuccess()
+
+		return nil
+	}))
+
+	for range 20 {
+		err := handler.Handle(context.TODO(), &xkafka.Message{})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, l.Limit())
+}
+
+func TestAdaptiveLimiter_ContextCancelled(t *testing.T) {
+	l := NewAdaptiveLimiter(InitialLimit(1), MaxLimit(1))
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+
+	handler := l.Middleware()(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		close(started)
+		<-block
+
+		return nil
+	}))
+
+	go func() { _ = handler.Handle(context.TODO(), &xkafka.Message{}) }()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := handler.Handle(ctx, &xkafka.Message{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(block)
+}
+
+func TestAdaptiveLimiter_BatchMiddleware(t *testing.T) {
+	l := NewAdaptiveLimiter(InitialLimit(4), BackoffRatio(0.5), ErrorRateThreshold(0.05))
+
+	handler := l.BatchMiddleware()(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+		return b.AckFail(assert.AnError)
+	}))
+
+	err := handler.HandleBatch(context.TODO(), xkafka.NewBatch())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestAdaptiveLimiter_ClampsLimits(t *testing.T) {
+	testcases := []struct {
+		name    string
+		opts    []Option
+		initial int
+	}{
+		{name: "zero initial limit", opts: []Option{InitialLimit(0)}, initial: 1},
+		{name: "zero min limit", opts: []Option{MinLimit(0), InitialLimit(0)}, initial: 1},
+		{name: "initial below min", opts: []Option{MinLimit(5), InitialLimit(2)}, initial: 5},
+		{name: "initial above max", opts: []Option{MaxLimit(5), InitialLimit(20)}, initial: 5},
+		{name: "max below min", opts: []Option{MinLimit(5), MaxLimit(2)}, initial: 5},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewAdaptiveLimiter(tc.opts...)
+
+			assert.Equal(t, tc.initial, l.Limit())
+
+			handler := l.Middleware()(xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+				m.AckFail(assert.AnError)
+
+				return nil
+			}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			// the limit never drops below 1, so messages keep flowing
+			for range 50 {
+				assert.NoError(t, handler.Handle(ctx, &xkafka.Message{}))
+			}
+
+			assert.GreaterOrEqual(t, l.Limit(), 1)
+		})
+	}
+}
+
+func TestAdaptiveLimiter_InvalidBackoffRatio(t *testing.T) {
+	for _, ratio := range []float64{0, 1, -0.5, 1.5} {
+		assert.Panics(t, func() { NewAdaptiveLimiter(BackoffRatio(ratio)) })
+	}
+}
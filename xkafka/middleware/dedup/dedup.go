@@ -0,0 +1,194 @@
+// Package dedup provides a middleware that skips messages which
+// have already been processed.
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+// Store records the keys of processed messages.
+type Store interface {
+	// Exists reports whether the key has been recorded and has not expired.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Add records the key for the given TTL.
+	Add(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// Option configures the dedup middleware.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (f optionFunc) apply(c *config) { f(c) }
+
+// TTL sets the duration for which a processed message key is remembered.
+type TTL time.Duration
+
+func (t TTL) apply(c *config) { c.ttl = time.Duration(t) }
+
+// KeyFunc extracts the deduplication key from the message.
+// Messages with an empty key are never deduplicated.
+type KeyFunc func(msg *xkafka.Message) string
+
+func (fn KeyFunc) apply(c *config) { c.keyFn = fn }
+
+// ByMessageID uses xkafka.Message.ID as the deduplication key.
+func ByMessageID() Option {
+	return optionFunc(func(c *config) {
+		c.keyFn = func(msg *xkafka.Message) string { return msg.ID }
+	})
+}
+
+// ByHeader uses the value of the given header as the deduplication key.
+func ByHeader(name string) Option {
+	return optionFunc(func(c *config) {
+		c.keyFn = func(msg *xkafka.Message) string { return string(msg.Header(name)) }
+	})
+}
+
+type config struct {
+	ttl   time.Duration
+	keyFn KeyFunc
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		ttl:   24 * time.Hour,
+		keyFn: func(msg *xkafka.Message) string { return msg.ID },
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// Deduplicate is a middleware that marks already processed messages
+// as xkafka.Skip, without calling the next handler. A message is recorded
+// in the store once it is marked as xkafka.Success or xkafka.Skip.
+// Default values:
+// - TTL: 24 hours
+// - KeyFunc: ByMessageID
+func Deduplicate(store Store, opts ...Option) xkafka.MiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			key := cfg.keyFn(msg)
+			if key == "" {
+				return next.Handle(ctx, msg)
+			}
+
+			seen, err := store.Exists(ctx, key)
+			if err != nil {
+				return err
+			}
+
+			if seen {
+				msg.AckSkip()
+
+				return nil
+			}
+
+			if err := next.Handle(ctx, msg); err != nil {
+				return err
+			}
+
+			if msg.Status != xkafka.Success && msg.Status != xkafka.Skip {
+				return nil
+			}
+
+			return store.Add(ctx, key, cfg.ttl)
+		})
+	}
+}
+
+// BatchDeduplicate is a middleware that hands over only the messages which
+// have not been processed yet to the next handler, for xkafka.BatchConsumer.
+// Only the first message of each key in a batch is handed over.
+// The messages are recorded in the store once the batch is marked as
+// xkafka.Success or xkafka.Skip.
+func BatchDeduplicate(store Store, opts ...Option) xkafka.BatchMiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+			messages := make([]*xkafka.Message, 0, len(batch.Messages))
+			keys := make([]string, 0, len(batch.Messages))
+			inBatch := make(map[string]struct{}, len(batch.Messages))
+
+			for _, msg := range batch.Messages {
+				key := cfg.keyFn(msg)
+				if key != "" {
+					if _, ok := inBatch[key]; ok {
+						msg.AckSkip()
+
+						continue
+					}
+
+					seen, err := store.Exists(ctx, key)
+					if err != nil {
+						return err
+					}
+
+					if seen {
+						msg.AckSkip()
+
+						continue
+					}
+
+					keys = append(keys, key)
+					inBatch[key] = struct{}{}
+				}
+
+				messages = append(messages, msg)
+			}
+
+			if len(messages) == 0 {
+				batch.AckSkip()
+
+				return nil
+			}
+
+			// duplicates are kept in the original batch,
+			// so that their offsets are stored with the batch
+			filtered := xkafka.NewBatch()
+			filtered.ID = batch.ID
+			filtered.FlushReason = batch.FlushReason
+			filtered.Messages = messages
+
+			err := next.HandleBatch(ctx, filtered)
+
+			switch filtered.Status {
+			case xkafka.Success:
+				batch.AckSuccess()
+			case xkafka.Skip:
+				batch.AckSkip()
+			case xkafka.Fail:
+				_ = batch.AckFail(filtered.Err())
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if batch.Status != xkafka.Success && batch.Status != xkafka.Skip {
+				return nil
+			}
+
+			for _, key := range keys {
+				if err := store.Add(ctx, key, cfg.ttl); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+}
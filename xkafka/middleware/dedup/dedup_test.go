@@ -0,0 +1,212 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+type errStore struct{}
+
+func (errStore) Exists(context.Context, string) (bool, error) { return false, assert.AnError }
+
+func (errStore) Add(context.Context, string, time.Duration) error { return assert.AnError }
+
+func TestDeduplicate(t *testing.T) {
+	calls := 0
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		calls++
+
+		m.AckSuccess()
+
+		return nil
+	})
+
+	store := NewMemoryStore(10)
+	h := Deduplicate(store)(handler)
+
+	msg := &xkafka.Message{ID: "msg-1"}
+	require.NoError(t, h.Handle(context.TODO(), msg))
+	assert.Equal(t, xkafka.Success, msg.Status)
+
+	dup := &xkafka.Message{ID: "msg-1"}
+	require.NoError(t, h.Handle(context.TODO(), dup))
+	assert.Equal(t, xkafka.Skip, dup.Status)
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestDeduplicate_FailedMessageNotRecorded(t *testing.T) {
+	calls := 0
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		calls++
+
+		m.AckFail(assert.AnError)
+
+		return nil
+	})
+
+	h := Deduplicate(NewMemoryStore(10))(handler)
+
+	require.NoError(t, h.Handle(context.TODO(), &xkafka.Message{ID: "msg-1"}))
+	require.NoError(t, h.Handle(context.TODO(), &xkafka.Message{ID: "msg-1"}))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestDeduplicate_KeyFuncs(t *testing.T) {
+	testcases := []struct {
+		name string
+		opt  Option
+		msg  func() *xkafka.Message
+	}{
+		{
+			name: "header",
+			opt:  ByHeader("x-request-id"),
+			msg: func() *xkafka.Message {
+				m := &xkafka.Message{}
+				m.SetHeader("x-request-id", []byte("req-1"))
+
+				return m
+			},
+		},
+		{
+			name: "custom",
+			opt:  KeyFunc(func(m *xkafka.Message) string { return string(m.Key) }),
+			msg:  func() *xkafka.Message { return &xkafka.Message{Key: []byte("key-1")} },
+		},
+		{
+			name: "message id",
+			opt:  ByMessageID(),
+			msg:  func() *xkafka.Message { return &xkafka.Message{ID: "msg-1"} },
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+				calls++
+
+				m.AckSkip()
+
+				return nil
+			})
+
+			h := Deduplicate(NewMemoryStore(10), tc.opt, TTL(time.Minute))(handler)
+
+			require.NoError(t, h.Handle(context.TODO(), tc.msg()))
+			require.NoError(t, h.Handle(context.TODO(), tc.msg()))
+
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
+
+func TestDeduplicate_EmptyKey(t *testing.T) {
+	calls := 0
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		calls++
+
+		m.AckSuccess()
+
+		return nil
+	})
+
+	h := Deduplicate(errStore{})(handler)
+
+	require.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+	require.NoError(t, h.Handle(context.TODO(), &xkafka.Message{}))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestDeduplicate_StoreError(t *testing.T) {
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	})
+
+	h := Deduplicate(errStore{})(handler)
+
+	err := h.Handle(context.TODO(), &xkafka.Message{ID: "msg-1"})
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestBatchDeduplicate(t *testing.T) {
+	store := NewMemoryStore(10)
+	require.NoError(t, store.Add(context.TODO(), "msg-1", time.Minute))
+
+	batch := xkafka.NewBatch()
+	batch.Messages = []*xkafka.Message{
+		{ID: "msg-1", Offset: 3},
+		{ID: "msg-2", Offset: 4},
+	}
+
+	var handled []string
+
+	handler := BatchDeduplicate(store)(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+		for _, m := range b.Messages {
+			handled = append(handled, m.ID)
+		}
+
+		b.AckSuccess()
+
+		return nil
+	}))
+
+	require.NoError(t, handler.HandleBatch(context.TODO(), batch))
+
+	assert.Equal(t, []string{"msg-2"}, handled)
+	assert.Equal(t, xkafka.Success, batch.Status)
+	assert.Equal(t, xkafka.Skip, batch.Messages[0].Status)
+	assert.Equal(t, int64(4), batch.MaxOffset())
+
+	seen, err := store.Exists(context.TODO(), "msg-2")
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	t.Run("all duplicates", func(t *testing.T) {
+		batch := xkafka.NewBatch()
+		batch.Messages = []*xkafka.Message{{ID: "msg-1"}, {ID: "msg-2"}}
+
+		handled = nil
+
+		require.NoError(t, handler.HandleBatch(context.TODO(), batch))
+		assert.Empty(t, handled)
+		assert.Equal(t, xkafka.Skip, batch.Status)
+	})
+
+	t.Run("duplicates in batch", func(t *testing.T) {
+		batch := xkafka.NewBatch()
+		batch.Messages = []*xkafka.Message{{ID: "msg-4"}, {ID: "msg-5"}, {ID: "msg-4"}}
+
+		handled = nil
+
+		require.NoError(t, handler.HandleBatch(context.TODO(), batch))
+		assert.Equal(t, []string{"msg-4", "msg-5"}, handled)
+		assert.Equal(t, xkafka.Success, batch.Status)
+		assert.Equal(t, xkafka.Skip, batch.Messages[2].Status)
+	})
+
+	t.Run("failed batch", func(t *testing.T) {
+		handler := BatchDeduplicate(store)(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			return b.AckFail(assert.AnError)
+		}))
+
+		batch := xkafka.NewBatch()
+		batch.Messages = []*xkafka.Message{{ID: "msg-3"}}
+
+		err := handler.HandleBatch(context.TODO(), batch)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, xkafka.Fail, batch.Status)
+
+		seen, err := store.Exists(context.TODO(), "msg-3")
+		require.NoError(t, err)
+		assert.False(t, seen)
+	})
+}
@@ -0,0 +1,98 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store with least-recently-used eviction.
+// It is local to the process, and does not survive restarts.
+type MemoryStore struct {
+	size    int
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type entry struct {
+	key       string
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates a new MemoryStore that holds up to size keys.
+// A size less than 1 is raised to 1.
+func NewMemoryStore(size int) *MemoryStore {
+	size = max(size, 1)
+
+	return &MemoryStore{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// Exists reports whether the key has been recorded and has not expired.
+func (s *MemoryStore) Exists(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return false, nil
+	}
+
+	if time.Now().After(entryOf(el).expiresAt) {
+		s.remove(el)
+
+		return false, nil
+	}
+
+	s.lru.MoveToFront(el)
+
+	return true, nil
+}
+
+// Add records the key for the given TTL.
+func (s *MemoryStore) Add(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+
+	if el, ok := s.entries[key]; ok {
+		entryOf(el).expiresAt = expiresAt
+		s.lru.MoveToFront(el)
+
+		return nil
+	}
+
+	s.entries[key] = s.lru.PushFront(&entry{key: key, expiresAt: expiresAt})
+
+	for s.lru.Len() > s.size {
+		s.remove(s.lru.Back())
+	}
+
+	return nil
+}
+
+// Len returns the number of keys in the store, including expired ones
+// that have not been evicted yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lru.Len()
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.entries, entryOf(el).key)
+}
+
+func entryOf(el *list.Element) *entry {
+	e, _ := el.Value.(*entry)
+
+	return e
+}
@@ -0,0 +1,68 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_TTL(t *testing.T) {
+	s := NewMemoryStore(10)
+	ctx := context.TODO()
+
+	require.NoError(t, s.Add(ctx, "a", 10*time.Millisecond))
+
+	seen, err := s.Exists(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	time.Sleep(20 * time.Millisecond)
+
+	seen, err = s.Exists(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, seen)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewMemoryStore(2)
+	ctx := context.TODO()
+
+	require.NoError(t, s.Add(ctx, "a", time.Minute))
+	require.NoError(t, s.Add(ctx, "b", time.Minute))
+
+	// touch "a" so that "b" is the least recently used
+	seen, err := s.Exists(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	require.NoError(t, s.Add(ctx, "c", time.Minute))
+	require.NoError(t, s.Add(ctx, "a", time.Minute))
+
+	assert.Equal(t, 2, s.Len())
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		seen, err := s.Exists(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, want, seen, key)
+	}
+}
+
+func TestMemoryStore_MinimumSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		s := NewMemoryStore(size)
+		ctx := context.TODO()
+
+		require.NoError(t, s.Add(ctx, "a", time.Minute))
+
+		seen, err := s.Exists(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, seen)
+
+		require.NoError(t, s.Add(ctx, "b", time.Minute))
+		assert.Equal(t, 1, s.Len())
+	}
+}
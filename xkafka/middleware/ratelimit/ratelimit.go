@@ -0,0 +1,269 @@
+// Package ratelimit provides middlewares that cap the rate of message processing.
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+// Option configures the rate limit middleware.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (f optionFunc) apply(c *config) { f(c) }
+
+// Rate sets the number of messages allowed per second. It must be positive.
+type Rate float64
+
+func (r Rate) apply(c *config) { c.rate = float64(r) }
+
+// Burst sets the maximum number of messages that can be processed
+// at once, before the rate limit kicks in. It must be at least 1.
+type Burst int
+
+func (b Burst) apply(c *config) { c.burst = int(b) }
+
+// MaxKeys sets the maximum number of buckets kept. When a new key exceeds
+// it, idle buckets are evicted first, then the least recently used ones,
+// whose keys start again with a full bucket. A value less than 1 is raised
+// to 1. Used only with PerKey, PerTopic or KeyFunc.
+type MaxKeys int
+
+func (m MaxKeys) apply(c *config) { c.maxKeys = int(m) }
+
+// KeyFunc partitions the rate limit by the returned key.
+// Each key gets its own token bucket.
+type KeyFunc func(msg *xkafka.Message) string
+
+func (fn KeyFunc) apply(c *config) { c.keyFn = fn }
+
+// PerKey applies the rate limit to each message key separately.
+func PerKey() Option {
+	return optionFunc(func(c *config) {
+		c.keyFn = func(msg *xkafka.Message) string { return string(msg.Key) }
+	})
+}
+
+// PerTopic applies the rate limit to each topic separately.
+func PerTopic() Option {
+	return optionFunc(func(c *config) {
+		c.keyFn = func(msg *xkafka.Message) string { return msg.Topic }
+	})
+}
+
+type config struct {
+	rate    float64
+	burst   int
+	maxKeys int
+	keyFn   KeyFunc
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		rate:    100,
+		burst:   1,
+		maxKeys: 10000,
+		keyFn:   func(*xkafka.Message) string { return "" },
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	if c.rate <= 0 {
+		panic(fmt.Sprintf("[xkafka/ratelimit] Rate must be positive, got %v", c.rate))
+	}
+
+	if c.burst < 1 {
+		panic(fmt.Sprintf("[xkafka/ratelimit] Burst must be at least 1, got %d", c.burst))
+	}
+
+	c.maxKeys = max(c.maxKeys, 1)
+
+	return c
+}
+
+// RateLimit is a middleware that limits the rate at which messages are
+// handed over to the next handler, using a token bucket.
+// By default, a single bucket is shared by all messages of the consumer.
+// Use PerKey, PerTopic or KeyFunc to limit each key separately.
+// It panics if Rate is not positive, or Burst is less than 1.
+// Default values:
+// - Rate: 100 messages per second
+// - Burst: 1
+// - MaxKeys: 10000
+func RateLimit(opts ...Option) xkafka.MiddlewareFunc {
+	l := newLimiter(newConfig(opts...))
+
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			if err := l.wait(ctx, msg); err != nil {
+				return err
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
+// BatchRateLimit is a middleware that limits the rate of message processing
+// for xkafka.BatchConsumer. Each message in the batch consumes one token
+// of its bucket before the batch is handed over to the next handler.
+func BatchRateLimit(opts ...Option) xkafka.BatchMiddlewareFunc {
+	l := newLimiter(newConfig(opts...))
+
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
+			for _, msg := range batch.Messages {
+				if err := l.wait(ctx, msg); err != nil {
+					return err
+				}
+			}
+
+			return next.HandleBatch(ctx, batch)
+		})
+	}
+}
+
+type limiter struct {
+	cfg     *config
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	lru     *list.List
+}
+
+func newLimiter(cfg *config) *limiter {
+	return &limiter{
+		cfg:     cfg,
+		buckets: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (l *limiter) wait(ctx context.Context, msg *xkafka.Message) error {
+	b := l.bucket(l.cfg.keyFn(msg))
+
+	delay := b.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.cancel()
+
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *limiter) bucket(key string) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(el)
+
+		return el.Value.(*bucket)
+	}
+
+	if len(l.buckets) >= l.cfg.maxKeys {
+		l.evictIdle(time.Now())
+	}
+
+	for len(l.buckets) >= l.cfg.maxKeys {
+		l.remove(l.lru.Back())
+	}
+
+	b := &bucket{
+		key:    key,
+		rate:   l.cfg.rate,
+		burst:  float64(l.cfg.burst),
+		tokens: float64(l.cfg.burst),
+		last:   time.Now(),
+	}
+
+	l.buckets[key] = l.lru.PushFront(b)
+
+	return b
+}
+
+// evictIdle removes buckets that have refilled completely. Such buckets
+// carry no state, and are re-created on the next message for the key.
+func (l *limiter) evictIdle(now time.Time) {
+	for _, el := range l.buckets {
+		if el.Value.(*bucket).full(now) {
+			l.remove(el)
+		}
+	}
+}
+
+func (l *limiter) remove(el *list.Element) {
+	l.lru.Remove(el)
+	delete(l.buckets, el.Value.(*bucket).key)
+}
+
+type bucket struct {
+	key    string
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token from the bucket and returns the duration
+// to wait before the token is available.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token to the bucket.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+1, b.burst)
+}
+
+func (b *bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+
+	return b.tokens >= b.burst
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.last = now
+	b.tokens = min(b.tokens+elapsed.Seconds()*b.rate, b.burst)
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+func TestRateLimit(t *testing.T) {
+	count := 0
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		count++
+
+		return nil
+	})
+
+	mw := RateLimit(Rate(20), Burst(1))
+	h := mw(handler)
+
+	start := time.Now()
+
+	for range 5 {
+		err := h.Handle(context.TODO(), &xkafka.Message{Topic: "test-topic"})
+		assert.NoError(t, err)
+	}
+
+	// first message uses the burst, the remaining 4 wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+	assert.Equal(t, 5, count)
+}
+
+func TestRateLimit_PerKey(t *testing.T) {
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	})
+
+	mw := RateLimit(Rate(1), Burst(1), PerKey())
+	h := mw(handler)
+
+	start := time.Now()
+
+	for _, key := range []string{"a", "b", "c"} {
+		err := h.Handle(context.TODO(), &xkafka.Message{Key: []byte(key)})
+		assert.NoError(t, err)
+	}
+
+	// every key has its own bucket, so no message waits
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestRateLimit_ContextCancelled(t *testing.T) {
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		return nil
+	})
+
+	mw := RateLimit(Rate(0.1), Burst(1), PerTopic())
+	h := mw(handler)
+
+	err := h.Handle(context.TODO(), &xkafka.Message{Topic: "test-topic"})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = h.Handle(ctx, &xkafka.Message{Topic: "test-topic"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimit_EvictIdleBuckets(t *testing.T) {
+	l := newLimiter(newConfig(Rate(1000), MaxKeys(2), KeyFunc(func(m *xkafka.Message) string {
+		return string(m.Key)
+	})))
+
+	for _, key := range []string{"a", "b"} {
+		err := l.wait(context.TODO(), &xkafka.Message{Key: []byte(key)})
+		assert.NoError(t, err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	err := l.wait(context.TODO(), &xkafka.Message{Key: []byte("c")})
+	assert.NoError(t, err)
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestRateLimit_EvictLeastRecentlyUsed(t *testing.T) {
+	l := newLimiter(newConfig(Rate(1), MaxKeys(2), KeyFunc(func(m *xkafka.Message) string {
+		return string(m.Key)
+	})))
+
+	// no bucket refills, so none is idle
+	for _, key := range []string{"a", "b", "a", "c"} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_ = l.wait(ctx, &xkafka.Message{Key: []byte(key)})
+
+		cancel()
+	}
+
+	assert.Len(t, l.buckets, 2)
+	assert.Contains(t, l.buckets, "a")
+	assert.Contains(t, l.buckets, "c")
+}
+
+func TestBatchRateLimit(t *testing.T) {
+	batch := xkafka.NewBatch()
+
+	for range 3 {
+		batch.Messages = append(batch.Messages, &xkafka.Message{Topic: "test-topic"})
+	}
+
+	mw := BatchRateLimit(Rate(20), Burst(1))
+
+	start := time.Now()
+
+	err := mw(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+		return nil
+	})).HandleBatch(context.TODO(), batch)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRateLimit_InvalidOptions(t *testing.T) {
+	testcases := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "zero rate", opts: []Option{Rate(0)}},
+		{name: "negative rate", opts: []Option{Rate(-1)}},
+		{name: "zero burst", opts: []Option{Burst(0)}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Panics(t, func() { RateLimit(tc.opts...) })
+			assert.Panics(t, func() { BatchRateLimit(tc.opts...) })
+		})
+	}
+}
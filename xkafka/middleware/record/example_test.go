@@ -0,0 +1,69 @@
+package record_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xkafka/middleware/record"
+)
+
+func Example() {
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		m.AckSuccess()
+
+		return nil
+	})
+
+	consumer, err := xkafka.NewConsumer(
+		"record-consumer",
+		handler,
+		xkafka.Brokers{"localhost:9092"},
+		xkafka.Topics{"test-topic"},
+		xkafka.ErrorHandler(xkafka.NoopErrorHandler),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	f, err := os.Create("messages.ndjson")
+	if err != nil {
+		panic(err)
+	}
+
+	defer f.Close()
+
+	consumer.Use(record.Recorder(
+		record.NewWriter(f, record.JSON),
+		record.OnError(func(m *xkafka.Message, err error) {
+			slog.Error("failed to record message", "offset", m.Offset, "error", err)
+		}),
+	))
+
+	// ... run consumer
+}
+
+func ExampleReplay() {
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		// the handler under investigation
+		return nil
+	})
+
+	f, err := os.Open("messages.ndjson")
+	if err != nil {
+		panic(err)
+	}
+
+	defer f.Close()
+
+	err = record.Replay(
+		context.Background(),
+		record.NewReader(f, record.JSON),
+		handler,
+		record.PreserveTiming(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
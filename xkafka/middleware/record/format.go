@@ -0,0 +1,309 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Format is the encoding of a recording.
+type Format int
+
+// Format enums.
+const (
+	// JSON encodes one Record per line as JSON.
+	// Keys, values and headers are base64 encoded.
+	JSON Format = iota
+	// Binary encodes Records as length-prefixed fields.
+	// It is more compact than JSON, and preserves payloads byte for byte.
+	Binary
+)
+
+// binaryMagic is written at the start of binary recordings.
+var binaryMagic = []byte("XKR1")
+
+// maxFieldSize is the largest key, value or header accepted when reading
+// a binary recording. Larger lengths come from corrupt recordings.
+const maxFieldSize = 64 << 20
+
+// Record is a consumed message, as written to a recording.
+type Record struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       []byte            `json:"key,omitempty"`
+	Value     []byte            `json:"value,omitempty"`
+	Headers   map[string][]byte `json:"headers,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Writer writes Records to an io.Writer. It is safe for concurrent use.
+// Each Record is written with a single call to the underlying io.Writer.
+type Writer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Format
+	header bool
+}
+
+// NewWriter creates a new Writer.
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{w: w, format: format}
+}
+
+// Write writes the Record.
+func (w *Writer) Write(r Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var buf []byte
+
+	switch w.format {
+	case JSON:
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		buf = append(b, '\n')
+	case Binary:
+		if !w.header {
+			buf = append(buf, binaryMagic...)
+		}
+
+		buf = appendBinary(buf, r)
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownFormat, w.format)
+	}
+
+	if _, err := w.w.Write(buf); err != nil {
+		return err
+	}
+
+	w.header = true
+
+	return nil
+}
+
+// Reader reads Records from an io.Reader.
+type Reader struct {
+	r      *bufio.Reader
+	format Format
+	header bool
+}
+
+// NewReader creates a new Reader.
+func NewReader(r io.Reader, format Format) *Reader {
+	return &Reader{r: bufio.NewReader(r), format: format}
+}
+
+// Read returns the next Record, or io.EOF at the end of the recording.
+func (r *Reader) Read() (Record, error) {
+	switch r.format {
+	case JSON:
+		return r.readJSON()
+	case Binary:
+		return r.readBinary()
+	default:
+		return Record{}, fmt.Errorf("%w: %d", ErrUnknownFormat, r.format)
+	}
+}
+
+func (r *Reader) readJSON() (Record, error) {
+	var rec Record
+
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return rec, err
+			}
+
+			continue
+		}
+
+		if jerr := json.Unmarshal(line, &rec); jerr != nil {
+			return rec, fmt.Errorf("%w: %w", ErrCorrupt, jerr)
+		}
+
+		return rec, nil
+	}
+}
+
+func (r *Reader) readBinary() (Record, error) {
+	if !r.header {
+		magic := make([]byte, len(binaryMagic))
+		if _, err := io.ReadFull(r.r, magic); err != nil {
+			return Record{}, err
+		}
+
+		if !bytes.Equal(magic, binaryMagic) {
+			return Record{}, ErrCorrupt
+		}
+
+		r.header = true
+	}
+
+	if _, err := r.r.Peek(1); err != nil {
+		return Record{}, err
+	}
+
+	rec, err := readBinary(r.r)
+	if err != nil {
+		return rec, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+
+	return rec, nil
+}
+
+func appendBinary(buf []byte, r Record) []byte {
+	buf = appendBytes(buf, []byte(r.Topic))
+	buf = binary.AppendVarint(buf, int64(r.Partition))
+	buf = binary.AppendVarint(buf, r.Offset)
+	buf = appendTime(buf, r.Timestamp)
+	buf = appendBytes(buf, r.Key)
+	buf = appendBytes(buf, r.Value)
+
+	// sorted, so that recordings are reproducible
+	keys := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	buf = binary.AppendUvarint(buf, uint64(len(keys)))
+
+	for _, k := range keys {
+		buf = appendBytes(buf, []byte(k))
+		buf = appendBytes(buf, r.Headers[k])
+	}
+
+	return buf
+}
+
+// appendTime writes a presence flag, followed by the Unix nanoseconds of
+// non-zero times. The zero time is out of the range of UnixNano.
+func appendTime(buf []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return append(buf, 0)
+	}
+
+	buf = append(buf, 1)
+
+	return binary.AppendVarint(buf, t.UnixNano())
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+
+	return append(buf, b...)
+}
+
+// decoder reads binary fields, and stops at the first error.
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	var v int64
+
+	v, d.err = binary.ReadVarint(d.r)
+
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	var v uint64
+
+	v, d.err = binary.ReadUvarint(d.r)
+
+	return v
+}
+
+func (d *decoder) time() time.Time {
+	if d.err != nil {
+		return time.Time{}
+	}
+
+	var flag byte
+
+	flag, d.err = d.r.ReadByte()
+
+	switch {
+	case d.err != nil || flag == 0:
+		return time.Time{}
+	case flag != 1:
+		d.err = fmt.Errorf("invalid timestamp flag %d", flag)
+
+		return time.Time{}
+	}
+
+	return time.Unix(0, d.varint()).UTC()
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil || n == 0 {
+		return nil
+	}
+
+	if n > maxFieldSize {
+		d.err = fmt.Errorf("field of %d bytes exceeds %d bytes", n, maxFieldSize)
+
+		return nil
+	}
+
+	// read without allocating n bytes upfront, as a truncated
+	// recording can have fewer bytes left than its length claims
+	b, err := io.ReadAll(io.LimitReader(d.r, int64(n)))
+
+	switch {
+	case err != nil:
+		d.err = err
+	case uint64(len(b)) < n:
+		d.err = io.ErrUnexpectedEOF
+	}
+
+	return b
+}
+
+func readBinary(r *bufio.Reader) (Record, error) {
+	d := &decoder{r: r}
+
+	rec := Record{
+		Topic:     string(d.bytes()),
+		Partition: int32(d.varint()),
+		Offset:    d.varint(),
+		Timestamp: d.time(),
+		Key:       d.bytes(),
+		Value:     d.bytes(),
+	}
+
+	n := d.uvarint()
+	if n > 0 {
+		rec.Headers = make(map[string][]byte)
+	}
+
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		k := d.bytes()
+		rec.Headers[string(k)] = d.bytes()
+	}
+
+	return rec, d.err
+}
@@ -0,0 +1,256 @@
+// Package record provides a middleware that records consumed messages to a file,
+// and a replayer that feeds a recording into a handler without a broker.
+//
+// Recordings are useful to reproduce production incidents locally:
+// record the messages with Recorder, copy the file, and replay it
+// into the same handler with Replay or ReplayBatch.
+package record
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+var (
+	// ErrUnknownFormat is returned when the Format is not supported.
+	ErrUnknownFormat = errors.New("[xkafka/record] unknown format")
+	// ErrCorrupt is returned when a recording cannot be decoded.
+	ErrCorrupt = errors.New("[xkafka/record] corrupt recording")
+)
+
+// Option configures the recorder and replayer.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (f optionFunc) apply(c *config) { f(c) }
+
+// OnError sets a callback for errors while writing a recording.
+// Recording errors never fail the message.
+func OnError(fn func(msg *xkafka.Message, err error)) Option {
+	return optionFunc(func(c *config) { c.onError = fn })
+}
+
+// PreserveTiming replays messages with the same delays
+// between them as their original timestamps.
+type PreserveTiming bool
+
+func (p PreserveTiming) apply(c *config) { c.preserveTiming = bool(p) }
+
+// BatchSize sets the number of messages per batch for ReplayBatch.
+type BatchSize int
+
+func (b BatchSize) apply(c *config) { c.batchSize = int(b) }
+
+// Group sets the consumer group of the replayed messages.
+type Group string
+
+func (g Group) apply(c *config) { c.group = string(g) }
+
+type config struct {
+	onError        func(msg *xkafka.Message, err error)
+	preserveTiming bool
+	batchSize      int
+	group          string
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		batchSize: 100,
+		group:     "replay",
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// Recorder is a middleware that writes every consumed message
+// to the Writer, before passing it to the next handler.
+func Recorder(w *Writer, opts ...Option) xkafka.MiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			cfg.record(w, msg)
+
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
+// BatchRecorder is a middleware that writes every message of
+// the batch to the Writer, before passing it to the next handler.
+func BatchRecorder(w *Writer, opts ...Option) xkafka.BatchMiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			for _, msg := range b.Messages {
+				cfg.record(w, msg)
+			}
+
+			return next.HandleBatch(ctx, b)
+		})
+	}
+}
+
+func (c *config) record(w *Writer, msg *xkafka.Message) {
+	err := w.Write(Record{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   maps.Clone(msg.Headers()),
+		Timestamp: msg.Timestamp,
+	})
+	if err != nil && c.onError != nil {
+		c.onError(msg, err)
+	}
+}
+
+// Replay reads the recording and feeds every message to the handler, in order.
+// It stops at the end of the recording, when the context is cancelled, or
+// at the first error returned by the handler.
+// Default values:
+// - PreserveTiming: false
+// - Group: "replay"
+func Replay(ctx context.Context, r *Reader, handler xkafka.Handler, opts ...Option) error {
+	cfg := newConfig(opts...)
+	clock := &replayClock{enabled: cfg.preserveTiming}
+
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := clock.wait(ctx, rec.Timestamp); err != nil {
+			return err
+		}
+
+		if err := handler.Handle(ctx, newMessage(cfg.group, rec)); err != nil {
+			return err
+		}
+	}
+}
+
+// ReplayBatch reads the recording and feeds the messages to the handler
+// in batches of BatchSize. With PreserveTiming, a batch is handled at the
+// timestamp of its last message.
+// Default values:
+// - BatchSize: 100
+// - PreserveTiming: false
+// - Group: "replay"
+func ReplayBatch(ctx context.Context, r *Reader, handler xkafka.BatchHandler, opts ...Option) error {
+	cfg := newConfig(opts...)
+	clock := &replayClock{enabled: cfg.preserveTiming}
+	batch := xkafka.NewBatch()
+
+	flush := func() error {
+		if len(batch.Messages) == 0 {
+			return nil
+		}
+
+		last := batch.Messages[len(batch.Messages)-1]
+
+		if err := clock.wait(ctx, last.Timestamp); err != nil {
+			return err
+		}
+
+		if err := handler.HandleBatch(ctx, batch); err != nil {
+			return err
+		}
+
+		batch = xkafka.NewBatch()
+
+		return nil
+	}
+
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		batch.Messages = append(batch.Messages, newMessage(cfg.group, rec))
+
+		if len(batch.Messages) >= cfg.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newMessage(group string, rec Record) *xkafka.Message {
+	msg := &xkafka.Message{
+		ID:        string(rec.Headers[xkafka.HeaderMessageID]),
+		Topic:     rec.Topic,
+		Partition: rec.Partition,
+		Group:     group,
+		Key:       rec.Key,
+		Value:     rec.Value,
+		Timestamp: rec.Timestamp,
+		Offset:    rec.Offset,
+	}
+
+	for k, v := range rec.Headers {
+		msg.SetHeader(k, v)
+	}
+
+	return msg
+}
+
+// replayClock maps the timestamps of a recording to wall-clock time,
+// starting from the first message.
+type replayClock struct {
+	enabled bool
+	first   time.Time
+	start   time.Time
+}
+
+func (c *replayClock) wait(ctx context.Context, ts time.Time) error {
+	if !c.enabled {
+		return ctx.Err()
+	}
+
+	if c.start.IsZero() {
+		c.first, c.start = ts, time.Now()
+
+		return ctx.Err()
+	}
+
+	delay := time.Until(c.start.Add(ts.Sub(c.first)))
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
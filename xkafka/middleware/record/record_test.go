@@ -0,0 +1,226 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+var formats = map[string]Format{"json": JSON, "binary": Binary}
+
+func newTestMessage(offset int64, ts time.Time) *xkafka.Message {
+	msg := &xkafka.Message{
+		ID:        "message-id",
+		Topic:     "test-topic",
+		Partition: 2,
+		Offset:    offset,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Timestamp: ts,
+	}
+
+	msg.SetHeader(xkafka.HeaderMessageID, []byte("message-id"))
+	msg.SetHeader("trace-id", []byte("trace"))
+
+	return msg
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			ts := time.Date(2024, 1, 1, 0, 0, 0, 123, time.UTC)
+			recorded := []*xkafka.Message{newTestMessage(10, ts), newTestMessage(11, ts.Add(time.Second))}
+
+			handler := Recorder(NewWriter(&buf, format))(xkafka.HandlerFunc(
+				func(ctx context.Context, msg *xkafka.Message) error {
+					msg.AckSuccess()
+
+					return nil
+				},
+			))
+
+			for _, msg := range recorded {
+				require.NoError(t, handler.Handle(context.Background(), msg))
+			}
+
+			var replayed []*xkafka.Message
+
+			err := Replay(context.Background(), NewReader(&buf, format), xkafka.HandlerFunc(
+				func(ctx context.Context, msg *xkafka.Message) error {
+					replayed = append(replayed, msg)
+
+					return nil
+				},
+			))
+			require.NoError(t, err)
+			require.Len(t, replayed, 2)
+
+			for i, msg := range replayed {
+				assert.Equal(t, recorded[i].ID, msg.ID)
+				assert.Equal(t, recorded[i].Topic, msg.Topic)
+				assert.Equal(t, recorded[i].Partition, msg.Partition)
+				assert.Equal(t, recorded[i].Offset, msg.Offset)
+				assert.Equal(t, recorded[i].Key, msg.Key)
+				assert.Equal(t, recorded[i].Value, msg.Value)
+				assert.Equal(t, recorded[i].Headers(), msg.Headers())
+				assert.True(t, recorded[i].Timestamp.Equal(msg.Timestamp))
+				assert.Equal(t, "replay", msg.Group)
+				assert.Equal(t, xkafka.Unassigned, msg.Status)
+			}
+		})
+	}
+}
+
+func TestReplayBatch(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, Binary)
+	handler := BatchRecorder(w)(xkafka.BatchHandlerFunc(
+		func(ctx context.Context, b *xkafka.Batch) error { return nil },
+	))
+
+	b := xkafka.NewBatch()
+	for i := range 5 {
+		b.Messages = append(b.Messages, newTestMessage(int64(i), time.Now()))
+	}
+
+	require.NoError(t, handler.HandleBatch(context.Background(), b))
+
+	var sizes []int
+
+	err := ReplayBatch(context.Background(), NewReader(&buf, Binary), xkafka.BatchHandlerFunc(
+		func(ctx context.Context, b *xkafka.Batch) error {
+			sizes = append(sizes, len(b.Messages))
+
+			return nil
+		},
+	), BatchSize(2), Group("debug"))
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+}
+
+func TestReplayPreserveTiming(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, JSON)
+	ts := time.Now()
+
+	for i, d := range []time.Duration{0, 50 * time.Millisecond} {
+		require.NoError(t, w.Write(Record{Topic: "test-topic", Offset: int64(i), Timestamp: ts.Add(d)}))
+	}
+
+	start := time.Now()
+
+	err := Replay(context.Background(), NewReader(&buf, JSON), xkafka.HandlerFunc(
+		func(ctx context.Context, msg *xkafka.Message) error { return nil },
+	), PreserveTiming(true))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestReplayHandlerError(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, JSON)
+	require.NoError(t, w.Write(Record{Topic: "test-topic"}))
+	require.NoError(t, w.Write(Record{Topic: "test-topic"}))
+
+	calls := 0
+
+	err := Replay(context.Background(), NewReader(&buf, JSON), xkafka.HandlerFunc(
+		func(ctx context.Context, msg *xkafka.Message) error {
+			calls++
+
+			return assert.AnError
+		},
+	))
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 1, calls)
+}
+
+func TestReaderCorrupt(t *testing.T) {
+	_, err := NewReader(bytes.NewBufferString("not-a-recording"), Binary).Read()
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	_, err = NewReader(bytes.NewBufferString("{\n"), JSON).Read()
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	var buf bytes.Buffer
+	require.NoError(t, NewWriter(&buf, Binary).Write(Record{Topic: "test-topic", Value: []byte("value")}))
+
+	_, err = NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]), Binary).Read()
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	_, err = NewReader(&bytes.Buffer{}, JSON).Read()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReaderOversizedField(t *testing.T) {
+	buf := append([]byte{}, binaryMagic...)
+	// topic length of 1<<40 bytes, with no bytes following
+	buf = binary.AppendUvarint(buf, 1<<40)
+
+	_, err := NewReader(bytes.NewReader(buf), Binary).Read()
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	// length within the limit, but longer than the recording
+	buf = append([]byte{}, binaryMagic...)
+	buf = binary.AppendUvarint(buf, 1<<20)
+	buf = append(buf, "test-topic"...)
+
+	_, err = NewReader(bytes.NewReader(buf), Binary).Read()
+	assert.ErrorIs(t, err, ErrCorrupt)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestBinaryZeroTimestamp(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, Binary)
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, w.Write(Record{Topic: "test-topic"}))
+	require.NoError(t, w.Write(Record{Topic: "test-topic", Timestamp: ts}))
+
+	r := NewReader(&buf, Binary)
+
+	rec, err := r.Read()
+	require.NoError(t, err)
+	assert.True(t, rec.Timestamp.IsZero())
+
+	rec, err = r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, ts, rec.Timestamp)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, assert.AnError }
+
+func TestRecorderOnError(t *testing.T) {
+	var recErr error
+
+	handler := Recorder(NewWriter(failingWriter{}, JSON), OnError(func(_ *xkafka.Message, err error) {
+		recErr = err
+	}))(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		msg.AckSuccess()
+
+		return nil
+	}))
+
+	msg := newTestMessage(1, time.Now())
+
+	require.NoError(t, handler.Handle(context.Background(), msg))
+	assert.Equal(t, xkafka.Success, msg.Status)
+	assert.ErrorIs(t, recErr, assert.AnError)
+}
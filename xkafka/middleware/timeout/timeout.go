@@ -0,0 +1,305 @@
+// Package timeout provides middlewares that limit the time a handler
+// can spend on a message or a batch.
+//
+// A handler that does not return blocks its partition, until
+// `max.poll.interval.ms` expires and the consumer is removed from the group.
+// The middlewares run the handler with a context deadline, and fail the
+// message with an *Error when it expires. Handlers that ignore the context
+// and keep running after the deadline are reported as leaked.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+// ErrPanic is returned when the handler panics. The panic is recovered in
+// the goroutine that runs the handler, and the message is failed.
+var ErrPanic = errors.New("[xkafka/timeout] handler panicked")
+
+// Error is returned when the handler does not finish within the timeout.
+type Error struct {
+	Timeout time.Duration
+	// Leaked is true if the handler was still running after the grace period.
+	Leaked bool
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("[xkafka/timeout] handler did not finish within %s", e.Timeout)
+}
+
+// Unwrap allows matching the error with context.DeadlineExceeded.
+func (e *Error) Unwrap() error { return context.DeadlineExceeded }
+
+// Option configures the timeout middleware.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (f optionFunc) apply(c *config) { f(c) }
+
+// GracePeriod sets how long to wait for the handler to return after its
+// context is cancelled, before it is reported as leaked.
+type GracePeriod time.Duration
+
+func (g GracePeriod) apply(c *config) { c.grace = time.Duration(g) }
+
+// OnLeak sets a callback for handlers that are still running after
+// the grace period. The message must not be modified by the callback.
+func OnLeak(fn func(msg *xkafka.Message)) Option {
+	return optionFunc(func(c *config) { c.onLeak = fn })
+}
+
+// Leaks counts the handlers that are still running after their
+// context was cancelled. It can be passed as an Option, and shared
+// between middlewares, to export the count as a metric.
+type Leaks struct {
+	n atomic.Int64
+}
+
+// Count returns the number of handlers currently leaked.
+func (l *Leaks) Count() int64 { return l.n.Load() }
+
+func (l *Leaks) apply(c *config) { c.leaks = l }
+
+type config struct {
+	grace  time.Duration
+	onLeak func(msg *xkafka.Message)
+	leaks  *Leaks
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		grace: time.Second,
+		leaks: &Leaks{},
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// Timeout is a middleware that cancels the handler context after d, and
+// fails the message with an *Error if the handler has not returned.
+//
+// The handler runs on a copy of the message. Acks of the copy are applied
+// to the message, and trigger its callbacks, until the deadline expires.
+// A leaked handler cannot change the message after it is failed.
+// Default values:
+// - GracePeriod: 1s
+func Timeout(d time.Duration, opts ...Option) xkafka.MiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.Handler) xkafka.Handler {
+		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+			fwd := &forwarder{}
+			clone := fwd.clone(msg)
+
+			leaked, err := cfg.run(ctx, d, func(ctx context.Context) error {
+				return next.Handle(ctx, clone)
+			})
+
+			fwd.stop()
+
+			if leaked {
+				cfg.leak(msg)
+			}
+
+			var terr *Error
+			if errors.As(err, &terr) {
+				terr.Leaked = leaked
+			}
+
+			if terr != nil || errors.Is(err, ErrPanic) {
+				msg.AckFail(err)
+			}
+
+			return err
+		})
+	}
+}
+
+// BatchTimeout is a middleware that cancels the batch handler context
+// after d, and fails the batch with an *Error if the handler has not returned.
+// Like Timeout, the handler runs on a copy of the batch.
+// Default values:
+// - GracePeriod: 1s
+func BatchTimeout(d time.Duration, opts ...Option) xkafka.BatchMiddlewareFunc {
+	cfg := newConfig(opts...)
+
+	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
+		return xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			fwd := &forwarder{}
+
+			clone := xkafka.NewBatch()
+			clone.ID = b.ID
+			clone.FlushReason = b.FlushReason
+
+			for _, msg := range b.Messages {
+				clone.Messages = append(clone.Messages, fwd.clone(msg))
+			}
+
+			leaked, err := cfg.run(ctx, d, func(ctx context.Context) error {
+				return next.HandleBatch(ctx, clone)
+			})
+
+			fwd.stop()
+
+			if leaked {
+				for _, msg := range b.Messages {
+					cfg.leak(msg)
+				}
+			}
+
+			var terr *Error
+			if errors.As(err, &terr) {
+				terr.Leaked = leaked
+			}
+
+			if terr != nil || errors.Is(err, ErrPanic) {
+				return b.AckFail(err)
+			}
+
+			if !leaked {
+				ackBatchWith(b, clone)
+			}
+
+			return err
+		})
+	}
+}
+
+// run calls fn with a context that expires after d. It reports a leak
+// if fn is still running after the grace period following the cancellation.
+func (c *config) run(ctx context.Context, d time.Duration, fn func(ctx context.Context) error) (bool, error) {
+	ctx, cancel := context.WithTimeoutCause(ctx, d, &Error{Timeout: d})
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			// the handler goroutine can not be covered by a recover
+			// middleware of the caller, so its panics are returned
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%w: %+v", ErrPanic, r)
+			}
+		}()
+
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return false, err
+	case <-ctx.Done():
+	}
+
+	cause := context.Cause(ctx)
+
+	timer := time.NewTimer(c.grace)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		// the handler gave up on the cancelled context
+		return false, cause
+	case <-timer.C:
+	}
+
+	c.leaks.n.Add(1)
+
+	go func() {
+		<-done
+		c.leaks.n.Add(-1)
+	}()
+
+	return true, cause
+}
+
+func (c *config) leak(msg *xkafka.Message) {
+	if c.onLeak != nil {
+		c.onLeak(msg)
+	}
+}
+
+// forwarder applies the acks of message copies to the original messages,
+// until it is stopped.
+type forwarder struct {
+	mu      sync.Mutex
+	stopped bool
+}
+
+// clone returns a copy of the message, whose acks are forwarded to msg.
+func (f *forwarder) clone(msg *xkafka.Message) *xkafka.Message {
+	clone := &xkafka.Message{
+		ID:        msg.ID,
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Group:     msg.Group,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Timestamp: msg.Timestamp,
+		Offset:    msg.Offset,
+	}
+
+	for k, v := range msg.Headers() {
+		clone.SetHeader(k, v)
+	}
+
+	// added first, so that it runs after the callbacks of the handler
+	clone.AddCallback(func(c *xkafka.Message) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if !f.stopped {
+			ackWith(msg, c)
+		}
+	})
+
+	return clone
+}
+
+// stop stops forwarding acks. Handlers that are still running
+// can no longer change the original messages.
+func (f *forwarder) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stopped = true
+}
+
+func ackWith(msg, clone *xkafka.Message) {
+	switch clone.Status {
+	case xkafka.Success:
+		msg.AckSuccess()
+	case xkafka.Skip:
+		msg.AckSkip()
+	case xkafka.Fail:
+		msg.AckFail(clone.Err())
+	case xkafka.Unassigned:
+	}
+}
+
+func ackBatchWith(b, clone *xkafka.Batch) {
+	switch clone.Status {
+	case xkafka.Success:
+		b.AckSuccess()
+	case xkafka.Skip:
+		b.AckSkip()
+	case xkafka.Fail:
+		_ = b.AckFail(clone.Err())
+	case xkafka.Unassigned:
+	}
+}
@@ -0,0 +1,213 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+func newTestMessage() *xkafka.Message {
+	msg := &xkafka.Message{Topic: "test-topic", Key: []byte("key"), Value: []byte("value")}
+	msg.SetHeader("trace-id", []byte("trace"))
+
+	return msg
+}
+
+func TestTimeoutInTime(t *testing.T) {
+	t.Parallel()
+
+	handler := Timeout(time.Second)(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, []byte("trace"), msg.Header("trace-id"))
+
+		msg.AckSuccess()
+
+		return nil
+	}))
+
+	msg := newTestMessage()
+
+	err := handler.Handle(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, xkafka.Success, msg.Status)
+}
+
+func TestTimeoutHandlerError(t *testing.T) {
+	t.Parallel()
+
+	handler := Timeout(time.Second)(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		msg.AckFail(assert.AnError)
+
+		return assert.AnError
+	}))
+
+	msg := newTestMessage()
+
+	err := handler.Handle(context.Background(), msg)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, xkafka.Fail, msg.Status)
+	assert.ErrorIs(t, msg.Err(), assert.AnError)
+}
+
+func TestTimeoutExpired(t *testing.T) {
+	t.Parallel()
+
+	var leaked []*xkafka.Message
+
+	handler := Timeout(10*time.Millisecond, OnLeak(func(msg *xkafka.Message) {
+		leaked = append(leaked, msg)
+	}))(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}))
+
+	msg := newTestMessage()
+
+	err := handler.Handle(context.Background(), msg)
+
+	var terr *Error
+	require.ErrorAs(t, err, &terr)
+	assert.Equal(t, 10*time.Millisecond, terr.Timeout)
+	assert.False(t, terr.Leaked)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, xkafka.Fail, msg.Status)
+	assert.Empty(t, leaked)
+}
+
+func TestTimeoutLeak(t *testing.T) {
+	t.Parallel()
+
+	var (
+		leaks   = &Leaks{}
+		leaked  []*xkafka.Message
+		release = make(chan struct{})
+	)
+
+	handler := Timeout(
+		10*time.Millisecond,
+		GracePeriod(10*time.Millisecond),
+		leaks,
+		OnLeak(func(msg *xkafka.Message) { leaked = append(leaked, msg) }),
+	)(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		// ignores ctx cancellation
+		<-release
+
+		msg.AckSuccess()
+
+		return nil
+	}))
+
+	msg := newTestMessage()
+
+	err := handler.Handle(context.Background(), msg)
+
+	var terr *Error
+	require.ErrorAs(t, err, &terr)
+	assert.True(t, terr.Leaked)
+	assert.Equal(t, []*xkafka.Message{msg}, leaked)
+	assert.EqualValues(t, 1, leaks.Count())
+
+	close(release)
+
+	assert.Eventually(t, func() bool { return leaks.Count() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, xkafka.Fail, msg.Status)
+}
+
+func TestBatchTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("in time", func(t *testing.T) {
+		handler := BatchTimeout(time.Second)(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			b.Messages[0].AckSkip()
+			b.AckSuccess()
+
+			return nil
+		}))
+
+		b := xkafka.NewBatch()
+		b.Messages = append(b.Messages, newTestMessage(), newTestMessage())
+
+		require.NoError(t, handler.HandleBatch(context.Background(), b))
+		assert.Equal(t, xkafka.Success, b.Status)
+		assert.Equal(t, xkafka.Skip, b.Messages[0].Status)
+		assert.Equal(t, xkafka.Unassigned, b.Messages[1].Status)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		handler := BatchTimeout(10 * time.Millisecond)(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}))
+
+		b := xkafka.NewBatch()
+		b.Messages = append(b.Messages, newTestMessage())
+
+		err := handler.HandleBatch(context.Background(), b)
+
+		var terr *Error
+		assert.True(t, errors.As(err, &terr))
+		assert.Equal(t, xkafka.Fail, b.Status)
+		assert.ErrorIs(t, b.Err(), context.DeadlineExceeded)
+	})
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	t.Parallel()
+
+	handler := Timeout(time.Second)(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		panic("boom")
+	}))
+
+	msg := newTestMessage()
+
+	err := handler.Handle(context.Background(), msg)
+	assert.ErrorIs(t, err, ErrPanic)
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, xkafka.Fail, msg.Status)
+
+	t.Run("batch", func(t *testing.T) {
+		handler := BatchTimeout(time.Second)(xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			panic("boom")
+		}))
+
+		b := xkafka.NewBatch()
+		b.Messages = append(b.Messages, newTestMessage())
+
+		err := handler.HandleBatch(context.Background(), b)
+		assert.ErrorIs(t, err, ErrPanic)
+		assert.Equal(t, xkafka.Fail, b.Status)
+	})
+}
+
+func TestTimeoutCallbacks(t *testing.T) {
+	t.Parallel()
+
+	var acks []xkafka.Status
+
+	handler := Timeout(time.Second)(xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
+		msg.AckFail(assert.AnError)
+		msg.AckSuccess()
+
+		return nil
+	}))
+
+	msg := newTestMessage()
+	msg.AddCallback(func(m *xkafka.Message) {
+		assert.Same(t, msg, m)
+
+		acks = append(acks, m.Status)
+	})
+
+	require.NoError(t, handler.Handle(context.Background(), msg))
+	assert.Equal(t, []xkafka.Status{xkafka.Fail, xkafka.Success}, acks)
+	assert.Equal(t, xkafka.Success, msg.Status)
+}
@@ -0,0 +1,100 @@
+package xkafka
+
+import (
+	"context"
+	"maps"
+)
+
+// Flow is a declarative pipeline of stages that messages go through,
+// before they reach a terminal handler. Flow is created with Pipeline.
+type Flow struct {
+	consumer *Consumer
+	stages   []MiddlewareFunc
+}
+
+// Pipeline creates a Flow for the messages consumed by the Consumer.
+// The Flow replaces the Consumer handler once it is terminated with
+// Flow.To or Flow.Handle. Middlewares added with Consumer.Use still
+// wrap the whole Flow.
+func Pipeline(consumer *Consumer) *Flow {
+	return &Flow{consumer: consumer}
+}
+
+// Filter drops the messages for which fn returns false.
+// Dropped messages are marked as Skip.
+func (f *Flow) Filter(fn func(msg *Message) bool) *Flow {
+	return f.Use(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if !fn(msg) {
+				msg.AckSkip()
+
+				return nil
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	})
+}
+
+// Map transforms the messages in place, for example to enrich the
+// value or headers, or to change the key. If fn returns an error,
+// the message is marked as Fail and the error is returned.
+func (f *Flow) Map(fn func(ctx context.Context, msg *Message) error) *Flow {
+	return f.Use(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if err := fn(ctx, msg); err != nil {
+				msg.AckFail(err)
+
+				return err
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	})
+}
+
+// Use appends middlewares as stages of the Flow.
+func (f *Flow) Use(mwf ...MiddlewareFunc) *Flow {
+	f.stages = append(f.stages, mwf...)
+
+	return f
+}
+
+// To terminates the Flow by publishing the messages to the topic with the
+// Producer. The key, value and headers of the message are published
+// synchronously. The consumed message is marked as Success only after the
+// Producer receives the delivery report, so its offset is stored only once
+// the message is delivered downstream.
+func (f *Flow) To(producer *Producer, topic string) *Consumer {
+	return f.Handle(HandlerFunc(func(ctx context.Context, msg *Message) error {
+		out := &Message{
+			ID:      msg.ID,
+			Topic:   topic,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			headers: maps.Clone(msg.headers),
+		}
+
+		if err := producer.Publish(ctx, out); err != nil {
+			msg.AckFail(err)
+
+			return err
+		}
+
+		msg.AckSuccess()
+
+		return nil
+	}))
+}
+
+// Handle terminates the Flow with the handler, and sets the
+// Flow as the Consumer handler.
+func (f *Flow) Handle(handler Handler) *Consumer {
+	for i := len(f.stages) - 1; i >= 0; i-- {
+		handler = f.stages[i].Middleware(handler)
+	}
+
+	f.consumer.handler = handler
+
+	return f.consumer
+}
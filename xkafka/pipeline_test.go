@@ -0,0 +1,121 @@
+package xkafka
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	t.Parallel()
+
+	consumer, _ := newTestConsumer(t, defaultOpts...)
+	producer, mockProducer := newTestProducer(t)
+
+	var published *kafka.Message
+
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		published = args.Get(0).(*kafka.Message)
+
+		go func() {
+			args.Get(1).(chan kafka.Event) <- published
+		}()
+	}).Return(nil).Once()
+
+	c := Pipeline(consumer).
+		Filter(func(msg *Message) bool {
+			return !bytes.Equal(msg.Key, []byte("drop"))
+		}).
+		Map(func(ctx context.Context, msg *Message) error {
+			msg.Key = bytes.ToUpper(msg.Key)
+			msg.SetHeader("x-bridged", []byte("true"))
+
+			return nil
+		}).
+		To(producer, "output-topic")
+
+	assert.Same(t, consumer, c)
+
+	dropped := newMessage("consumer-id", newFakeKafkaMessage())
+	dropped.Key = []byte("drop")
+
+	err := consumer.handler.Handle(context.Background(), dropped)
+	require.NoError(t, err)
+	assert.Equal(t, Skip, dropped.Status)
+
+	msg := newMessage("consumer-id", newFakeKafkaMessage())
+	msg.ID = "message-id"
+
+	err = consumer.handler.Handle(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, Success, msg.Status)
+
+	require.NotNil(t, published)
+	assert.Equal(t, "output-topic", *published.TopicPartition.Topic)
+	assert.Equal(t, []byte("KEY"), published.Key)
+	assert.Equal(t, []byte("value"), published.Value)
+	assert.ElementsMatch(t, []kafka.Header{
+		{Key: "x-bridged", Value: []byte("true")},
+		{Key: HeaderMessageID, Value: []byte("message-id")},
+	}, published.Headers)
+
+	producer.Close()
+	mockProducer.AssertExpectations(t)
+}
+
+func TestPipeline_DeliveryFailure(t *testing.T) {
+	t.Parallel()
+
+	consumer, _ := newTestConsumer(t, defaultOpts...)
+	producer, mockProducer := newTestProducer(t)
+
+	mockProducer.On("Produce", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		km := *args.Get(0).(*kafka.Message)
+		km.TopicPartition.Error = assert.AnError
+
+		go func() {
+			args.Get(1).(chan kafka.Event) <- &km
+		}()
+	}).Return(nil).Once()
+
+	Pipeline(consumer).To(producer, "output-topic")
+
+	msg := newMessage("consumer-id", newFakeKafkaMessage())
+
+	err := consumer.handler.Handle(context.Background(), msg)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Fail, msg.Status)
+
+	producer.Close()
+	mockProducer.AssertExpectations(t)
+}
+
+func TestPipeline_MapError(t *testing.T) {
+	t.Parallel()
+
+	consumer, _ := newTestConsumer(t, defaultOpts...)
+
+	called := false
+
+	Pipeline(consumer).
+		Map(func(ctx context.Context, msg *Message) error {
+			return assert.AnError
+		}).
+		Handle(HandlerFunc(func(ctx context.Context, msg *Message) error {
+			called = true
+
+			return nil
+		}))
+
+	msg := newMessage("consumer-id", newFakeKafkaMessage())
+
+	err := consumer.handler.Handle(context.Background(), msg)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, Fail, msg.Status)
+	assert.False(t, called)
+}
@@ -0,0 +1,151 @@
+package xkafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// OAuthBearerToken is the token set on the kafka client
+// after an OAUTHBEARER token refresh.
+type OAuthBearerToken = kafka.OAuthBearerToken
+
+// StatsCallback is called with the librdkafka statistics, as a JSON
+// string, every `statistics.interval.ms`. It can be used with consumers
+// and producers. Statistics are disabled unless `statistics.interval.ms`
+// is set with ConfigMap.
+type StatsCallback func(stats string)
+
+func (cb StatsCallback) setConsumerConfig(o *consumerConfig) { o.statsCb = cb }
+
+func (cb StatsCallback) setProducerConfig(o *producerConfig) { o.statsCb = cb }
+
+// PartitionEOFCallback is called when the consumer reaches the end of
+// a partition, with the offset of the next message. Setting the
+// callback enables `enable.partition.eof`.
+type PartitionEOFCallback func(topic string, partition int32, offset int64)
+
+func (cb PartitionEOFCallback) setConsumerConfig(o *consumerConfig) {
+	o.partitionEOFCb = cb
+	_ = o.configMap.SetKey("enable.partition.eof", true)
+}
+
+// OAuthBearerTokenRefreshCallback is called when the OAUTHBEARER token
+// needs to be refreshed, with the `sasl.oauthbearer.config` value.
+// The returned token is set on the kafka client. If an error is
+// returned, the client is notified of the failure and retries later.
+type OAuthBearerTokenRefreshCallback func(oauthConfig string) (OAuthBearerToken, error)
+
+func (cb OAuthBearerTokenRefreshCallback) setConsumerConfig(o *consumerConfig) {
+	o.oauthRefresh = cb.refresh
+}
+
+func (cb OAuthBearerTokenRefreshCallback) setProducerConfig(o *producerConfig) {
+	o.oauthRefresh = cb.refresh
+}
+
+func (cb OAuthBearerTokenRefreshCallback) refresh(_ context.Context, oauthConfig string) (OAuthBearerToken, error) {
+	return cb(oauthConfig)
+}
+
+// poller polls the kafka client in a separate goroutine, one event
+// per request, so that the run loops can wait for the next event
+// together with the context and timers.
+type poller struct {
+	client   consumerClient
+	timeout  int
+	requests chan struct{}
+	events   chan kafka.Event
+	done     chan struct{}
+}
+
+func newPoller(client consumerClient, cfg *consumerConfig) *poller {
+	p := &poller{
+		client:   client,
+		timeout:  int(cfg.pollTimeout.Milliseconds()),
+		requests: make(chan struct{}, 1),
+		events:   make(chan kafka.Event, 1),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+
+		for range p.requests {
+			p.events <- p.client.Poll(p.timeout)
+		}
+	}()
+
+	return p
+}
+
+// poll requests the next event, and returns the channel it is sent on.
+// Only one poll must be in flight at a time.
+func (p *poller) poll() <-chan kafka.Event {
+	p.requests <- struct{}{}
+
+	return p.events
+}
+
+// stop waits for the poll in flight, if any, to return.
+func (p *poller) stop() {
+	close(p.requests)
+	<-p.done
+}
+
+// handleEvent returns the kafka message of message events, and the error
+// of error events. Other events are passed to their callbacks.
+func (c *consumerConfig) handleEvent(ctx context.Context, client consumerClient, e kafka.Event) (*kafka.Message, error) {
+	switch ev := e.(type) {
+	case *kafka.Message:
+		if ev.TopicPartition.Error != nil {
+			return nil, ev.TopicPartition.Error
+		}
+
+		return ev, nil
+	case kafka.Error:
+		// timeouts were reported as errors by ReadMessage,
+		// and are still not surfaced for compatibility
+		if ev.Code() == kafka.ErrTimedOut {
+			return nil, nil
+		}
+
+		return nil, ev
+	case *kafka.Stats:
+		if c.statsCb != nil {
+			c.statsCb(ev.String())
+		}
+	case kafka.PartitionEOF:
+		if c.partitionEOFCb != nil && ev.Topic != nil {
+			c.partitionEOFCb(*ev.Topic, ev.Partition, int64(ev.Offset))
+		}
+	case kafka.OAuthBearerTokenRefresh:
+		return nil, c.refreshOAuthBearerToken(ctx, client, ev)
+	}
+
+	return nil, nil
+}
+
+// firstError keeps the first error reported by the concurrent
+// handlers of a run loop.
+type firstError struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (f *firstError) set(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+func (f *firstError) get() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
@@ -0,0 +1,111 @@
+package xkafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerEventCallbacks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		stats []string
+		eofs  []int64
+		token = OAuthBearerToken{TokenValue: "token"}
+	)
+
+	consumer, mockKafka := newTestConsumer(t, append(defaultOpts,
+		StatsCallback(func(s string) { stats = append(stats, s) }),
+		PartitionEOFCallback(func(topic string, partition int32, offset int64) {
+			assert.Equal(t, "test-topic", topic)
+			eofs = append(eofs, offset)
+		}),
+		OAuthBearerTokenRefreshCallback(func(oauthConfig string) (OAuthBearerToken, error) {
+			assert.Equal(t, "scope=test", oauthConfig)
+
+			return token, nil
+		}),
+	)...)
+
+	enabled, err := consumer.config.configMap.Get("enable.partition.eof", false)
+	require.NoError(t, err)
+	assert.Equal(t, true, enabled)
+
+	topic := "test-topic"
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockKafka.On("SubscribeTopics", []string(testTopics), mock.Anything).Return(nil)
+	mockKafka.On("Unsubscribe").Return(nil)
+	mockKafka.On("Commit").Return(nil, nil)
+	mockKafka.On("Close").Return(nil)
+	mockKafka.On("SetOAuthBearerToken", token).Return(nil).Once()
+	mockKafka.On("Poll", testPollTimeout).Return(&kafka.Stats{}).Once()
+	mockKafka.On("Poll", testPollTimeout).Return(kafka.OAuthBearerTokenRefresh{Config: "scope=test"}).Once()
+	mockKafka.On("Poll", testPollTimeout).Return(nil).Once()
+	mockKafka.On("Poll", testPollTimeout).Return(kafka.PartitionEOF{Topic: &topic, Partition: 1, Offset: 42}).Once()
+	mockKafka.On("Poll", testPollTimeout).Return(nil).Run(func(mock.Arguments) { cancel() })
+
+	consumer.handler = HandlerFunc(func(ctx context.Context, msg *Message) error {
+		t.Fatal("unexpected message")
+
+		return nil
+	})
+
+	err = consumer.Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{""}, stats)
+	assert.Equal(t, []int64{42}, eofs)
+
+	mockKafka.AssertExpectations(t)
+}
+
+func TestConsumerOAuthBearerTokenRefreshFailure(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := newConsumerConfig(append(defaultOpts,
+		OAuthBearerTokenRefreshCallback(func(string) (OAuthBearerToken, error) {
+			return OAuthBearerToken{}, assert.AnError
+		}),
+	)...)
+	require.NoError(t, err)
+
+	mockKafka := NewMockConsumerClient(t)
+	mockKafka.On("SetOAuthBearerTokenFailure", assert.AnError.Error()).Return(nil).Once()
+
+	// the client retries the refresh, so the consumer keeps running
+	km, err := cfg.handleEvent(context.Background(), mockKafka, kafka.OAuthBearerTokenRefresh{})
+	assert.Nil(t, km)
+	assert.NoError(t, err)
+}
+
+func TestHandleEventErrors(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := newConsumerConfig(defaultOpts...)
+	require.NoError(t, err)
+
+	km, err := cfg.handleEvent(context.Background(), nil, kafka.NewError(kafka.ErrTimedOut, "timed out", false))
+	assert.Nil(t, km)
+	assert.NoError(t, err)
+
+	expect := kafka.NewError(kafka.ErrAllBrokersDown, "all brokers down", false)
+	_, err = cfg.handleEvent(context.Background(), nil, expect)
+	assert.ErrorIs(t, err, expect)
+
+	msg := newFakeKafkaMessage()
+	msg.TopicPartition.Error = assert.AnError
+
+	km, err = cfg.handleEvent(context.Background(), nil, msg)
+	assert.Nil(t, km)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	km, err = cfg.handleEvent(context.Background(), nil, nil)
+	assert.Nil(t, km)
+	assert.NoError(t, err)
+}
@@ -3,21 +3,28 @@ package xkafka
 import (
 	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/pkg/errors"
+	"github.com/rs/xid"
 )
 
 // Producer manages the production of messages to kafka topics.
 // It provides both synchronous and asynchronous publish methods
 // and a channel to stream delivery events.
 type Producer struct {
+	name                string
 	config              *producerConfig
 	kafka               producerClient
 	events              chan kafka.Event
 	middlewares         []Middlewarer
 	wrappedPublish      Handler
 	wrappedAsyncPublish Handler
+
+	closeMu sync.RWMutex
+	closed  bool
 }
 
 // NewProducer creates a new Producer.
@@ -31,12 +38,22 @@ func NewProducer(name string, opts ...ProducerOption) (*Producer, error) {
 	_ = cfg.configMap.SetKey("bootstrap.servers", strings.Join(cfg.brokers, ","))
 	_ = cfg.configMap.SetKey("client.id", name)
 
+	if cfg.idempotent {
+		_ = cfg.configMap.SetKey("enable.idempotence", true)
+
+		// idempotence requires acks from all in-sync replicas
+		if tc, ok := cfg.configMap["default.topic.config"].(kafka.ConfigMap); ok {
+			tc["acks"] = "all"
+		}
+	}
+
 	producer, err := cfg.producerFn(&cfg.configMap)
 	if err != nil {
 		return nil, err
 	}
 
 	p := &Producer{
+		name:   name,
 		config: cfg,
 		kafka:  producer,
 		events: producer.Events(),
@@ -48,6 +65,23 @@ func NewProducer(name string, opts ...ProducerOption) (*Producer, error) {
 	return p, nil
 }
 
+// Name returns the name of the producer, which is also its client ID.
+func (p *Producer) Name() string { return p.name }
+
+// Len returns the number of messages waiting to be sent,
+// or to be acknowledged by the brokers. It returns 0 once
+// the producer is closed.
+func (p *Producer) Len() int {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+
+	if p.closed {
+		return 0
+	}
+
+	return p.kafka.Len()
+}
+
 // Use appends a MiddlewareFunc to the chain.
 // Middleware can be used to intercept or otherwise modify, process or skip messages.
 // They are executed in the order that they are applied to the Producer.
@@ -70,14 +104,23 @@ func (p *Producer) Use(mwf ...MiddlewareFunc) {
 }
 
 // Start starts the kafka event handling.
-// It blocks until the context is cancelled.
+// It blocks until the context is cancelled, or the ErrorHandler
+// returns an error for a non-delivery event.
 func (p *Producer) Start(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case e := <-p.events:
-			_ = p.handleEvent(e)
+			err := p.handleEvent(ctx, e)
+			if _, ok := e.(*kafka.Message); ok || err == nil {
+				// delivery errors are reported with the message
+				continue
+			}
+
+			if ferr := p.config.errorHandler(classifyError(err)); ferr != nil {
+				return ferr
+			}
 		}
 	}
 }
@@ -91,12 +134,15 @@ func (p *Producer) Run(ctx context.Context) error {
 
 // AsyncPublish sends messages to the kafka topic asyncronously.
 func (p *Producer) AsyncPublish(ctx context.Context, msg *Message) error {
+	setMessageID(msg)
+
 	return p.wrappedAsyncPublish.Handle(ctx, msg)
 }
 
 func (p *Producer) asyncPublish() HandlerFunc {
 	return func(ctx context.Context, msg *Message) error {
 		km := newKafkaMessage(msg)
+		msg.enqueuedAt = time.Now()
 
 		p.kafka.ProduceChannel() <- km
 
@@ -107,6 +153,8 @@ func (p *Producer) asyncPublish() HandlerFunc {
 // Publish sends messages to the kafka topic synchronously.
 // Returns error if the message cannot be enqueued or if there's a Kafka error.
 func (p *Producer) Publish(ctx context.Context, msg *Message) error {
+	setMessageID(msg)
+
 	return p.wrappedPublish.Handle(ctx, msg)
 }
 
@@ -116,6 +164,7 @@ func (p *Producer) publish() HandlerFunc {
 		defer close(deliveryChan)
 
 		m := newKafkaMessage(msg)
+		msg.enqueuedAt = time.Now()
 
 		err := p.kafka.Produce(m, deliveryChan)
 		if err != nil {
@@ -128,12 +177,14 @@ func (p *Producer) publish() HandlerFunc {
 				p.config.deliveryCb(msg)
 			}
 
+			p.reportDelivery(msg, 0, err)
+
 			return re
 		}
 
 		e := <-deliveryChan
 
-		return p.handleEvent(e)
+		return p.handleEvent(ctx, e)
 	}
 }
 
@@ -141,10 +192,14 @@ func (p *Producer) publish() HandlerFunc {
 func (p *Producer) Close() {
 	p.kafka.Flush(int(p.config.shutdownTimeout.Milliseconds()))
 
+	p.closeMu.Lock()
+	defer p.closeMu.Unlock()
+
+	p.closed = true
 	p.kafka.Close()
 }
 
-func (p *Producer) handleEvent(e kafka.Event) error {
+func (p *Producer) handleEvent(ctx context.Context, e kafka.Event) error {
 	switch ev := e.(type) {
 	case *kafka.Message:
 		m, ok := ev.Opaque.(*Message)
@@ -162,12 +217,55 @@ func (p *Producer) handleEvent(e kafka.Event) error {
 			p.config.deliveryCb(m)
 		}
 
+		// messages not enqueued by this producer have no delivery latency
+		var latency time.Duration
+		if !m.enqueuedAt.IsZero() {
+			latency = time.Since(m.enqueuedAt)
+		}
+
+		p.reportDelivery(m, latency, ev.TopicPartition.Error)
+
 		return ev.TopicPartition.Error
+	case *kafka.Stats:
+		if p.config.statsCb != nil {
+			p.config.statsCb(ev.String())
+		}
+	case kafka.OAuthBearerTokenRefresh:
+		return p.config.refreshOAuthBearerToken(ctx, p.kafka, ev)
 	}
 
 	return nil
 }
 
+func (p *Producer) reportDelivery(m *Message, latency time.Duration, err error) {
+	if p.config.reportCb == nil {
+		return
+	}
+
+	report := DeliveryReport{
+		Producer: p.name,
+		Message:  m,
+		Latency:  latency,
+	}
+
+	var kerr kafka.Error
+	if errors.As(err, &kerr) {
+		report.ErrorCode = kerr.Code()
+	}
+
+	p.config.reportCb(report)
+}
+
+// setMessageID generates an ID for the message, unless one is set,
+// and carries it in the HeaderMessageID header.
+func setMessageID(msg *Message) {
+	if msg.ID == "" {
+		msg.ID = xid.New().String()
+	}
+
+	msg.SetHeader(HeaderMessageID, []byte(msg.ID))
+}
+
 func newKafkaMessage(msg *Message) *kafka.Message {
 	km := msg.asKafkaMessage()
 
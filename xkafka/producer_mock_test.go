@@ -47,6 +47,20 @@ func (_m *MockProducerClient) Flush(timeoutMs int) int {
 	return r0
 }
 
+// Len provides a mock function with given fields:
+func (_m *MockProducerClient) Len() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
 // Produce provides a mock function with given fields: msg, deliveryChan
 func (_m *MockProducerClient) Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error {
 	ret := _m.Called(msg, deliveryChan)
@@ -77,6 +91,34 @@ func (_m *MockProducerClient) ProduceChannel() chan *kafka.Message {
 	return r0
 }
 
+// SetOAuthBearerToken provides a mock function with given fields: oauthBearerToken
+func (_m *MockProducerClient) SetOAuthBearerToken(oauthBearerToken kafka.OAuthBearerToken) error {
+	ret := _m.Called(oauthBearerToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(kafka.OAuthBearerToken) error); ok {
+		r0 = rf(oauthBearerToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetOAuthBearerTokenFailure provides a mock function with given fields: errstr
+func (_m *MockProducerClient) SetOAuthBearerTokenFailure(errstr string) error {
+	ret := _m.Called(errstr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(errstr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewMockProducerClient interface {
 	mock.TestingT
 	Cleanup(func())
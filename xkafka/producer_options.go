@@ -22,6 +22,11 @@ type producerConfig struct {
 	shutdownTimeout time.Duration
 	producerFn      producerFunc
 	deliveryCb      DeliveryCallback
+	reportCb        DeliveryReportCallback
+	statsCb         StatsCallback
+	idempotent      bool
+
+	securityConfig
 }
 
 func newProducerConfig(opts ...ProducerOption) (*producerConfig, error) {
@@ -41,6 +46,8 @@ func newProducerConfig(opts ...ProducerOption) (*producerConfig, error) {
 		opt.setProducerConfig(cfg)
 	}
 
+	cfg.setSecurityProtocol(cfg.configMap)
+
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
@@ -67,3 +74,51 @@ type DeliveryCallback AckFunc
 func (d DeliveryCallback) setProducerConfig(o *producerConfig) {
 	o.deliveryCb = d
 }
+
+// DeliveryReport is the delivery report of a message published by the Producer.
+type DeliveryReport struct {
+	// Producer is the name of the producer.
+	Producer string
+	Message  *Message
+	// Latency is the time from the enqueue of the message to its delivery
+	// report. It is zero for messages that could not be enqueued.
+	Latency time.Duration
+	// ErrorCode is the Kafka error code of a failed delivery,
+	// or kafka.ErrNoError.
+	ErrorCode ErrorCode
+}
+
+// DeliveryReportCallback is a callback function triggered with the delivery
+// report of every published message, after the DeliveryCallback.
+// Works only for xkafka.Producer.
+type DeliveryReportCallback func(DeliveryReport)
+
+func (d DeliveryReportCallback) setProducerConfig(o *producerConfig) {
+	o.reportCb = d
+}
+
+// Idempotent enables the idempotent producer mode of librdkafka,
+// which guarantees that messages are written exactly once and in order
+// per partition, even when the producer retries. It also sets `acks`
+// to `all`, as required by the idempotent producer.
+type Idempotent bool
+
+func (i Idempotent) setProducerConfig(o *producerConfig) {
+	o.idempotent = bool(i)
+}
+
+// Compression sets the codec used to compress message batches.
+type Compression string
+
+// Compression codecs supported by librdkafka.
+const (
+	CompressionNone   Compression = "none"
+	CompressionGzip   Compression = "gzip"
+	CompressionSnappy Compression = "snappy"
+	CompressionLZ4    Compression = "lz4"
+	CompressionZstd   Compression = "zstd"
+)
+
+func (c Compression) setProducerConfig(o *producerConfig) {
+	_ = o.configMap.SetKey("compression.type", string(c))
+}
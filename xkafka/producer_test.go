@@ -46,6 +46,36 @@ func TestNewProducer(t *testing.T) {
 	assert.EqualValues(t, expectedConfig, producer.config.configMap)
 }
 
+func TestNewProducerIdempotent(t *testing.T) {
+	producer, err := NewProducer(
+		"test-producer",
+		append(defaultProducerOptions, Idempotent(true))...,
+	)
+	require.NoError(t, err)
+
+	expectedConfig := kafka.ConfigMap{
+		"bootstrap.servers":  "localhost:9092",
+		"client.id":          "test-producer",
+		"enable.idempotence": true,
+		"default.topic.config": kafka.ConfigMap{
+			"acks":        "all",
+			"partitioner": "consistent_random",
+		},
+	}
+
+	assert.EqualValues(t, expectedConfig, producer.config.configMap)
+}
+
+func TestNewProducerCompression(t *testing.T) {
+	producer, err := NewProducer(
+		"test-producer",
+		append(defaultProducerOptions, CompressionZstd)...,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "zstd", producer.config.configMap["compression.type"])
+}
+
 func TestNewProducerErrors(t *testing.T) {
 	testcases := []struct {
 		name    string
@@ -95,6 +125,9 @@ func TestProducerPublish(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 
 	callback := func(m *Message) {
@@ -143,6 +176,9 @@ func TestProducerPublishError(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 	expectErr := fmt.Errorf("kafka error")
 
@@ -196,6 +232,9 @@ func TestProducerPublishRetryableError(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 	expectErr := fmt.Errorf("enqueue error")
 
@@ -234,6 +273,9 @@ func TestProducerAsyncPublish(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -291,6 +333,9 @@ func TestProducerAsyncPublishError(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 
 	callback := func(m *Message) {
@@ -352,6 +397,9 @@ func TestProducerMiddlewareExecutionOrder(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 
 	mockKafka.On("Produce", km, mock.Anything).Run(func(args mock.Arguments) {
@@ -407,6 +455,9 @@ func TestProducerIgnoreOpaqueMessage(t *testing.T) {
 		Value:         msg.Value,
 		TimestampType: kafka.TimestampCreateTime,
 		Opaque:        msg,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(msg.ID)},
+		},
 	}
 
 	mockKafka.On("Produce", km, mock.Anything).Run(func(args mock.Arguments) {
@@ -443,6 +494,136 @@ func TestProducerIgnoreOpaqueMessage(t *testing.T) {
 	mockKafka.AssertExpectations(t)
 }
 
+func TestProducerPublishGeneratesMessageID(t *testing.T) {
+	producer, mockKafka := newTestProducer(t)
+
+	produceCh := make(chan *kafka.Message, 2)
+	mockKafka.On("ProduceChannel").Return(produceCh)
+
+	msg := &Message{Topic: testTopics[0], Value: []byte("value")}
+
+	err := producer.AsyncPublish(context.Background(), msg)
+	require.NoError(t, err)
+	require.NotEmpty(t, msg.ID)
+
+	got := <-produceCh
+	assert.Equal(t, []kafka.Header{{Key: HeaderMessageID, Value: []byte(msg.ID)}}, got.Headers)
+
+	other := &Message{Topic: testTopics[0], Value: []byte("value")}
+
+	err = producer.AsyncPublish(context.Background(), other)
+	require.NoError(t, err)
+	assert.NotEqual(t, msg.ID, other.ID)
+}
+
+func TestProducerStatsCallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var stats []string
+
+	producer, _ := newTestProducer(t, StatsCallback(func(s string) {
+		stats = append(stats, s)
+
+		cancel()
+	}))
+
+	producer.events <- &kafka.Stats{}
+
+	err := producer.Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{""}, stats)
+}
+
+func TestProducerDeliveryReportCallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var reports []DeliveryReport
+
+	producer, mockKafka := newTestProducer(t, DeliveryReportCallback(func(r DeliveryReport) {
+		reports = append(reports, r)
+
+		if len(reports) == 2 {
+			cancel()
+		}
+	}))
+
+	assert.Equal(t, "producer-id", producer.Name())
+
+	mockKafka.On("Len").Return(3)
+	mockKafka.On("ProduceChannel").Return(make(chan *kafka.Message, 2))
+
+	delivered, failed := newFakeMessage(), newFakeMessage()
+
+	require.NoError(t, producer.AsyncPublish(context.Background(), delivered))
+	require.NoError(t, producer.AsyncPublish(context.Background(), failed))
+	assert.Equal(t, 3, producer.Len())
+
+	time.Sleep(time.Millisecond)
+
+	producer.events <- newKafkaMessage(delivered)
+
+	timedOut := newKafkaMessage(failed)
+	timedOut.TopicPartition.Error = kafka.NewError(kafka.ErrMsgTimedOut, "message timed out", false)
+
+	go func() { producer.events <- timedOut }()
+
+	require.NoError(t, producer.Run(ctx))
+	require.Len(t, reports, 2)
+
+	assert.Equal(t, "producer-id", reports[0].Producer)
+	assert.Same(t, delivered, reports[0].Message)
+	assert.GreaterOrEqual(t, reports[0].Latency, time.Millisecond)
+	assert.Equal(t, kafka.ErrNoError, reports[0].ErrorCode)
+
+	assert.Same(t, failed, reports[1].Message)
+	assert.Equal(t, kafka.ErrMsgTimedOut, reports[1].ErrorCode)
+
+	// Run closes the producer
+	assert.Zero(t, producer.Len())
+}
+
+func TestProducerDeliveryReportNotEnqueued(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var report DeliveryReport
+
+	producer, _ := newTestProducer(t, DeliveryReportCallback(func(r DeliveryReport) {
+		report = r
+
+		cancel()
+	}))
+
+	msg := newFakeMessage()
+
+	producer.events <- newKafkaMessage(msg)
+
+	require.NoError(t, producer.Run(ctx))
+
+	assert.Same(t, msg, report.Message)
+	assert.Zero(t, report.Latency)
+}
+
+func TestProducerDeliveryReportEnqueueError(t *testing.T) {
+	var report DeliveryReport
+
+	producer, mockKafka := newTestProducer(t, DeliveryReportCallback(func(r DeliveryReport) {
+		report = r
+	}))
+
+	mockKafka.On("Produce", mock.Anything, mock.Anything).
+		Return(kafka.NewError(kafka.ErrQueueFull, "queue full", false))
+
+	msg := newFakeMessage()
+
+	err := producer.Publish(context.Background(), msg)
+	assert.ErrorContains(t, err, ErrRetryable.Error())
+
+	assert.Same(t, msg, report.Message)
+	assert.Zero(t, report.Latency)
+	assert.Equal(t, kafka.ErrQueueFull, report.ErrorCode)
+}
+
 func newTestProducer(t *testing.T, opts ...ProducerOption) (*Producer, *MockProducerClient) {
 	mockKafka := &MockProducerClient{}
 
@@ -465,6 +646,7 @@ func newTestProducer(t *testing.T, opts ...ProducerOption) (*Producer, *MockProd
 
 func newFakeMessage() *Message {
 	return &Message{
+		ID:    "test-message-id",
 		Topic: testTopics[0],
 		Key:   []byte("test-key"),
 		Value: []byte("test-value"),
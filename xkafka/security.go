@@ -0,0 +1,244 @@
+package xkafka
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// SASLMechanism is the SASL mechanism used to authenticate with the brokers.
+type SASLMechanism string
+
+// SASL mechanisms supported by librdkafka.
+const (
+	SASLPlain       SASLMechanism = "PLAIN"
+	SASLScramSHA256 SASLMechanism = "SCRAM-SHA-256"
+	SASLScramSHA512 SASLMechanism = "SCRAM-SHA-512"
+	SASLOAuthBearer SASLMechanism = "OAUTHBEARER"
+)
+
+// TokenProvider returns a new OAUTHBEARER token. It is called when the
+// client starts, and every time librdkafka asks for a token refresh,
+// before the current token expires.
+type TokenProvider func(ctx context.Context) (OAuthBearerToken, error)
+
+// SASL configures SASL authentication. The fields can be loaded with xload.
+//
+// With SASLOAuthBearer, tokens are refreshed with the TokenProvider on every
+// OAUTHBEARER token refresh event. The Producer handles these events only
+// while it is running.
+type SASL struct {
+	// Mechanism defaults to SASLOAuthBearer if TokenProvider is set.
+	Mechanism SASLMechanism `env:"MECHANISM"`
+	// Username and Password are used with SASLPlain and SCRAM mechanisms.
+	Username string `env:"USERNAME"`
+	Password string `env:"PASSWORD"`
+	// TokenProvider is used with SASLOAuthBearer.
+	TokenProvider TokenProvider `env:"-"`
+}
+
+func (s SASL) setConsumerConfig(o *consumerConfig) { s.apply(o.configMap, &o.securityConfig) }
+
+func (s SASL) setProducerConfig(o *producerConfig) { s.apply(o.configMap, &o.securityConfig) }
+
+func (s SASL) apply(cm kafka.ConfigMap, sc *securityConfig) {
+	mechanism := s.Mechanism
+	if mechanism == "" && s.TokenProvider != nil {
+		mechanism = SASLOAuthBearer
+	}
+
+	if mechanism != "" {
+		_ = cm.SetKey("sasl.mechanism", string(mechanism))
+	}
+
+	if s.Username != "" {
+		_ = cm.SetKey("sasl.username", s.Username)
+		_ = cm.SetKey("sasl.password", s.Password)
+	}
+
+	if s.TokenProvider != nil {
+		sc.oauthRefresh = func(ctx context.Context, _ string) (OAuthBearerToken, error) {
+			return s.TokenProvider(ctx)
+		}
+	}
+
+	sc.sasl = true
+}
+
+// TLS configures TLS, and mutual TLS when a client certificate is set.
+// Certificates and keys are set either as file paths or as PEM strings,
+// and can be loaded with xload, for example from a secret store.
+//
+// Certificates are read by librdkafka when the client is created, and
+// running clients keep using them. Use ReloadOnRotation to re-create the
+// clients when the certificate files are rotated. PEM strings can not be
+// reloaded.
+type TLS struct {
+	CAFile      string `env:"CA_FILE"`
+	CertFile    string `env:"CERT_FILE"`
+	KeyFile     string `env:"KEY_FILE"`
+	KeyPassword string `env:"KEY_PASSWORD"`
+
+	CAPEM   string `env:"CA_PEM"`
+	CertPEM string `env:"CERT_PEM"`
+	KeyPEM  string `env:"KEY_PEM"`
+
+	// InsecureSkipVerify disables the verification of the broker certificates.
+	InsecureSkipVerify bool `env:"INSECURE_SKIP_VERIFY"`
+}
+
+func (t TLS) setConsumerConfig(o *consumerConfig) { t.apply(o.configMap, &o.securityConfig) }
+
+func (t TLS) setProducerConfig(o *producerConfig) { t.apply(o.configMap, &o.securityConfig) }
+
+func (t TLS) apply(cm kafka.ConfigMap, sc *securityConfig) {
+	for key, value := range map[string]string{
+		"ssl.ca.location":          t.CAFile,
+		"ssl.certificate.location": t.CertFile,
+		"ssl.key.location":         t.KeyFile,
+		"ssl.key.password":         t.KeyPassword,
+		"ssl.ca.pem":               t.CAPEM,
+		"ssl.certificate.pem":      t.CertPEM,
+		"ssl.key.pem":              t.KeyPEM,
+	} {
+		if value != "" {
+			_ = cm.SetKey(key, value)
+		}
+	}
+
+	if t.InsecureSkipVerify {
+		_ = cm.SetKey("enable.ssl.certificate.verification", false)
+	}
+
+	sc.tls = true
+}
+
+// ReloadOnRotation returns a RunFunc that calls run, and calls it again
+// with a new context when the content of CAFile, CertFile or KeyFile
+// changes. The files are checked every interval. run must create a new
+// Consumer, BatchConsumer or Producer on every call, for example:
+//
+//	tls.ReloadOnRotation(time.Minute, func(ctx context.Context) error {
+//		consumer, err := xkafka.NewConsumer("my-consumer", handler, tls, ...)
+//		if err != nil {
+//			return err
+//		}
+//
+//		return consumer.Run(ctx)
+//	})
+//
+// The RunFunc returns when run returns for another reason than a rotation.
+// It can be added to a Group.
+func (t TLS) ReloadOnRotation(interval time.Duration, run RunFunc) RunFunc {
+	return func(ctx context.Context) error {
+		for {
+			rotated, err := t.runUntilRotated(ctx, interval, run)
+			if !rotated || ctx.Err() != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (t TLS) runUntilRotated(ctx context.Context, interval time.Duration, run RunFunc) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sum := t.filesChecksum()
+
+	done := make(chan error, 1)
+
+	go func() { done <- run(ctx) }()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return false, err
+		case <-ticker.C:
+			// a file that can not be read is checked again on the next tick,
+			// as it can be in the middle of a rotation
+			if next := t.filesChecksum(); next != nil && string(next) != string(sum) {
+				cancel()
+				<-done
+
+				return true, nil
+			}
+		}
+	}
+}
+
+// filesChecksum returns a checksum of the certificate and key files,
+// or nil if a file can not be read.
+func (t TLS) filesChecksum() []byte {
+	h := sha256.New()
+
+	for _, path := range []string{t.CAFile, t.CertFile, t.KeyFile} {
+		if path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		_, _ = h.Write(b)
+	}
+
+	return h.Sum(nil)
+}
+
+// securityConfig is shared by the consumer and producer configs.
+type securityConfig struct {
+	sasl         bool
+	tls          bool
+	oauthRefresh func(ctx context.Context, oauthConfig string) (OAuthBearerToken, error)
+}
+
+// setSecurityProtocol sets `security.protocol` from the SASL and TLS
+// options, unless it is set with ConfigMap.
+func (sc *securityConfig) setSecurityProtocol(cm kafka.ConfigMap) {
+	if _, ok := cm["security.protocol"]; ok {
+		return
+	}
+
+	switch {
+	case sc.sasl && sc.tls:
+		_ = cm.SetKey("security.protocol", "SASL_SSL")
+	case sc.sasl:
+		_ = cm.SetKey("security.protocol", "SASL_PLAINTEXT")
+	case sc.tls:
+		_ = cm.SetKey("security.protocol", "SSL")
+	}
+}
+
+type oauthClient interface {
+	SetOAuthBearerToken(oauthBearerToken kafka.OAuthBearerToken) error
+	SetOAuthBearerTokenFailure(errstr string) error
+}
+
+// refreshOAuthBearerToken sets a new token on the client. Failures are
+// reported to the client, which keeps running and retries the refresh.
+// The client reports authentication errors once the current token expires.
+func (sc *securityConfig) refreshOAuthBearerToken(
+	ctx context.Context,
+	client oauthClient,
+	ev kafka.OAuthBearerTokenRefresh,
+) error {
+	if sc.oauthRefresh == nil {
+		return nil
+	}
+
+	token, err := sc.oauthRefresh(ctx, ev.Config)
+	if err != nil {
+		return client.SetOAuthBearerTokenFailure(err.Error())
+	}
+
+	return client.SetOAuthBearerToken(token)
+}
@@ -0,0 +1,185 @@
+package xkafka
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityOptions(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		options []ConsumerOption
+		expect  kafka.ConfigMap
+	}{
+		{
+			name: "sasl scram",
+			options: []ConsumerOption{
+				SASL{Mechanism: SASLScramSHA512, Username: "user", Password: "pass"},
+			},
+			expect: kafka.ConfigMap{
+				"security.protocol": "SASL_PLAINTEXT",
+				"sasl.mechanism":    "SCRAM-SHA-512",
+				"sasl.username":     "user",
+				"sasl.password":     "pass",
+			},
+		},
+		{
+			name: "mutual tls",
+			options: []ConsumerOption{
+				TLS{CAFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem"},
+			},
+			expect: kafka.ConfigMap{
+				"security.protocol":        "SSL",
+				"ssl.ca.location":          "ca.pem",
+				"ssl.certificate.location": "cert.pem",
+				"ssl.key.location":         "key.pem",
+			},
+		},
+		{
+			name: "oauthbearer over tls",
+			options: []ConsumerOption{
+				SASL{TokenProvider: func(ctx context.Context) (OAuthBearerToken, error) {
+					return OAuthBearerToken{}, nil
+				}},
+				TLS{CAPEM: "ca", InsecureSkipVerify: true},
+			},
+			expect: kafka.ConfigMap{
+				"security.protocol":                   "SASL_SSL",
+				"sasl.mechanism":                      "OAUTHBEARER",
+				"ssl.ca.pem":                          "ca",
+				"enable.ssl.certificate.verification": false,
+			},
+		},
+		{
+			name: "security protocol from config map",
+			options: []ConsumerOption{
+				ConfigMap{"security.protocol": "SASL_SSL"},
+				SASL{Mechanism: SASLPlain},
+			},
+			expect: kafka.ConfigMap{
+				"security.protocol": "SASL_SSL",
+				"sasl.mechanism":    "PLAIN",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := newConsumerConfig(append(defaultOpts, tc.options...)...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expect, cfg.configMap)
+		})
+	}
+}
+
+func TestProducerTokenProvider(t *testing.T) {
+	t.Parallel()
+
+	token := OAuthBearerToken{TokenValue: "token"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	producer, mockKafka := newTestProducer(t, SASL{
+		TokenProvider: func(ctx context.Context) (OAuthBearerToken, error) {
+			return token, nil
+		},
+	})
+
+	assert.Equal(t, "SASL_PLAINTEXT", producer.config.configMap["security.protocol"])
+
+	mockKafka.On("SetOAuthBearerToken", token).Return(nil).Once().Run(func(_ mock.Arguments) {
+		cancel()
+	})
+
+	producer.events <- kafka.OAuthBearerTokenRefresh{}
+
+	err := producer.Run(ctx)
+	require.NoError(t, err)
+
+	mockKafka.AssertExpectations(t)
+}
+
+func TestProducerTokenProviderError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	producer, mockKafka := newTestProducer(t,
+		SASL{
+			TokenProvider: func(ctx context.Context) (OAuthBearerToken, error) {
+				return OAuthBearerToken{}, assert.AnError
+			},
+		},
+		ErrorHandler(func(err error) error { return err }),
+	)
+
+	// the failure is reported to the client, which retries the refresh
+	mockKafka.On("SetOAuthBearerTokenFailure", assert.AnError.Error()).Return(nil).Once().Run(func(_ mock.Arguments) {
+		cancel()
+	})
+
+	producer.events <- kafka.OAuthBearerTokenRefresh{}
+
+	err := producer.Run(ctx)
+	require.NoError(t, err)
+
+	mockKafka.AssertExpectations(t)
+}
+
+func TestTLSReloadOnRotation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	require.NoError(t, os.WriteFile(certFile, []byte("cert-1"), 0o600))
+
+	tls := TLS{CertFile: certFile, KeyFile: filepath.Join(dir, "client.key")}
+	require.NoError(t, os.WriteFile(tls.KeyFile, []byte("key-1"), 0o600))
+
+	var runs atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- tls.ReloadOnRotation(5*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			<-ctx.Done()
+
+			return nil
+		})(ctx)
+	}()
+
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, os.WriteFile(certFile, []byte("cert-2"), 0o600))
+
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+
+	// unchanged files do not restart the client
+	time.Sleep(20 * time.Millisecond)
+	assert.EqualValues(t, 2, runs.Load())
+
+	cancel()
+	assert.NoError(t, <-errs)
+
+	t.Run("run error", func(t *testing.T) {
+		err := tls.ReloadOnRotation(time.Minute, func(context.Context) error {
+			return assert.AnError
+		})(context.Background())
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -0,0 +1,57 @@
+package window
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window is the half-open time interval [Start, End).
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Assigner assigns an event time to the windows that contain it.
+type Assigner interface {
+	Assign(t time.Time) []Window
+}
+
+// AssignerFunc implements Assigner interface.
+type AssignerFunc func(t time.Time) []Window
+
+// Assign implements Assigner interface.
+func (fn AssignerFunc) Assign(t time.Time) []Window { return fn(t) }
+
+// Tumbling returns an Assigner for fixed-size, non-overlapping windows.
+// Each event time belongs to exactly one window.
+// It panics if size is not positive.
+func Tumbling(size time.Duration) Assigner {
+	if size <= 0 {
+		panic(fmt.Sprintf("[xkafka/window] tumbling window size must be positive, got %s", size))
+	}
+
+	return AssignerFunc(func(t time.Time) []Window {
+		start := t.Truncate(size)
+
+		return []Window{{Start: start, End: start.Add(size)}}
+	})
+}
+
+// Sliding returns an Assigner for fixed-size windows that start every slide.
+// Each event time belongs to size/slide windows.
+// It panics unless 0 < slide <= size.
+func Sliding(size, slide time.Duration) Assigner {
+	if slide <= 0 || slide > size {
+		panic(fmt.Sprintf("[xkafka/window] sliding window needs 0 < slide <= size, got size %s, slide %s", size, slide))
+	}
+
+	return AssignerFunc(func(t time.Time) []Window {
+		var windows []Window
+
+		for start := t.Truncate(slide); t.Sub(start) < size; start = start.Add(-slide) {
+			windows = append(windows, Window{Start: start, End: start.Add(size)})
+		}
+
+		return windows
+	})
+}
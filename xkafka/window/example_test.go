@@ -0,0 +1,52 @@
+package window_test
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xkafka/window"
+)
+
+func Example() {
+	counts, err := window.New(window.Options[int]{
+		Assigner:        window.Tumbling(time.Minute),
+		Aggregate:       window.Count,
+		AllowedLateness: 10 * time.Second,
+		Emit: func(ctx context.Context, r window.Result[int]) error {
+			slog.Info("window closed", "key", r.Key, "start", r.Window.Start, "count", r.Value)
+
+			return nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	consumer, err := xkafka.NewBatchConsumer(
+		"window-consumer",
+		counts,
+		xkafka.Brokers{"localhost:9092"},
+		xkafka.Topics{"test-topic"},
+		xkafka.BatchSize(100),
+		xkafka.BatchTimeout(time.Second),
+		xkafka.ErrorHandler(func(err error) error {
+			slog.Error(err.Error())
+
+			return nil
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+
+	if err := consumer.Run(ctx); err != nil {
+		panic(err)
+	}
+
+	// emit the open windows once the consumer stops
+	_ = counts.Flush(ctx)
+}
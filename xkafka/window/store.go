@@ -0,0 +1,97 @@
+package window
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Key identifies the window of a message key. The window bounds are
+// Unix nanoseconds, so that the same instants make the same key,
+// regardless of their location or monotonic clock reading.
+type Key struct {
+	Key   string
+	Start int64
+	End   int64
+}
+
+// NewKey returns the Key of the window for the message key.
+func NewKey(key string, w Window) Key {
+	return Key{Key: key, Start: w.Start.UnixNano(), End: w.End.UnixNano()}
+}
+
+// Window returns the window of the key, in UTC.
+func (k Key) Window() Window {
+	return Window{
+		Start: time.Unix(0, k.Start).UTC(),
+		End:   time.Unix(0, k.End).UTC(),
+	}
+}
+
+// Store keeps the aggregates of open windows. Errors returned by the
+// Store fail the batch, which is handled by the xkafka.ErrorHandler.
+type Store[A any] interface {
+	// Get returns the aggregate of the window, if present.
+	Get(ctx context.Context, k Key) (A, bool, error)
+	// Put sets the aggregate of the window.
+	Put(ctx context.Context, k Key, value A) error
+	// Delete removes the window.
+	Delete(ctx context.Context, k Key) error
+	// Keys returns the keys of all the windows in the store.
+	Keys(ctx context.Context) ([]Key, error)
+}
+
+// MemoryStore is an in-memory Store.
+// The open windows are lost when the process restarts.
+type MemoryStore[A any] struct {
+	mu      sync.Mutex
+	windows map[Key]A
+}
+
+// NewMemoryStore creates a new MemoryStore.
+func NewMemoryStore[A any]() *MemoryStore[A] {
+	return &MemoryStore[A]{windows: make(map[Key]A)}
+}
+
+// Get returns the aggregate of the window, if present.
+func (s *MemoryStore[A]) Get(_ context.Context, k Key) (A, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.windows[k]
+
+	return v, ok, nil
+}
+
+// Put sets the aggregate of the window.
+func (s *MemoryStore[A]) Put(_ context.Context, k Key, value A) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.windows[k] = value
+
+	return nil
+}
+
+// Delete removes the window.
+func (s *MemoryStore[A]) Delete(_ context.Context, k Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.windows, k)
+
+	return nil
+}
+
+// Keys returns the keys of all the windows in the store.
+func (s *MemoryStore[A]) Keys(_ context.Context) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]Key, 0, len(s.windows))
+	for k := range s.windows {
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,265 @@
+// Package window provides event-time windowed aggregations for xkafka.BatchConsumer.
+//
+// Messages are assigned to windows using Message.Timestamp and grouped by
+// Message.Key. A window is closed once the watermark passes its end. The
+// aggregate of a closed window is emitted and removed from the Store.
+//
+// The watermark is tracked per topic partition: it is the lowest of the
+// highest event times seen on each partition, minus the allowed lateness.
+// It advances once per batch, after all the messages of the batch are
+// aggregated, so messages that are out of order within a batch, or that
+// come from a partition lagging behind the others, are not dropped as late.
+// A partition that stops receiving messages, for example after it is
+// revoked, holds the watermark back; Flush emits the remaining windows.
+//
+// Aggregates are updated before the batch offsets are committed, so a
+// restart may aggregate a message twice (at-least-once), and windows kept
+// in a MemoryStore are lost on restart. Use a durable Store when this matters.
+package window
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+// ErrInvalidOptions is returned when a required option is missing.
+var ErrInvalidOptions = errors.New("[xkafka/window] invalid options")
+
+// AggregateFunc folds a message into the aggregate of a window.
+// The aggregate is the zero value of A for a new window.
+type AggregateFunc[A any] func(acc A, msg *xkafka.Message) A
+
+// EmitFunc is called with the aggregate of a closed window.
+type EmitFunc[A any] func(ctx context.Context, r Result[A]) error
+
+// Result is the aggregate of a closed window for a message key.
+type Result[A any] struct {
+	Key    string
+	Window Window
+	Value  A
+}
+
+// Options configures an Aggregator.
+type Options[A any] struct {
+	// Assigner assigns messages to windows. Required.
+	Assigner Assigner
+	// Aggregate folds messages into the window aggregate. Required.
+	Aggregate AggregateFunc[A]
+	// Emit is called for each closed window. Required.
+	Emit EmitFunc[A]
+	// Store keeps the open windows. Defaults to a MemoryStore.
+	Store Store[A]
+	// AllowedLateness is how far behind the highest event time of its
+	// partition a message can be before its windows are closed.
+	// Defaults to 0.
+	AllowedLateness time.Duration
+	// OnLate is called for messages that arrive after all their
+	// windows are closed. Late messages are dropped.
+	OnLate func(msg *xkafka.Message)
+}
+
+// Aggregator aggregates messages into windows. It implements
+// xkafka.BatchHandler and can be used with xkafka.NewBatchConsumer.
+type Aggregator[A any] struct {
+	opts      Options[A]
+	mu        sync.Mutex
+	maxEvents map[partition]time.Time
+	watermark time.Time
+}
+
+type partition struct {
+	topic     string
+	partition int32
+}
+
+// New creates a new Aggregator.
+func New[A any](opts Options[A]) (*Aggregator[A], error) {
+	if opts.Assigner == nil || opts.Aggregate == nil || opts.Emit == nil {
+		return nil, ErrInvalidOptions
+	}
+
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore[A]()
+	}
+
+	return &Aggregator[A]{opts: opts, maxEvents: make(map[partition]time.Time)}, nil
+}
+
+// Watermark returns the current watermark. Windows ending at or
+// before the watermark are closed.
+func (a *Aggregator[A]) Watermark() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.watermark
+}
+
+// HandleBatch implements xkafka.BatchHandler. It aggregates the messages,
+// then advances the watermark and emits the windows closed by it.
+func (a *Aggregator[A]) HandleBatch(ctx context.Context, batch *xkafka.Batch) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, msg := range batch.Messages {
+		if err := a.add(ctx, msg); err != nil {
+			return batch.AckFail(err)
+		}
+	}
+
+	a.advance()
+
+	if err := a.emit(ctx, false); err != nil {
+		return batch.AckFail(err)
+	}
+
+	batch.AckSuccess()
+
+	return nil
+}
+
+// Flush emits all the open windows, regardless of the watermark.
+// Call it before shutdown when the Store does not outlive the process.
+func (a *Aggregator[A]) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.emit(ctx, true)
+}
+
+func (a *Aggregator[A]) add(ctx context.Context, msg *xkafka.Message) error {
+	t := msg.Timestamp
+	late := true
+
+	for _, w := range a.opts.Assigner.Assign(t) {
+		if !w.End.After(a.watermark) {
+			continue
+		}
+
+		late = false
+		k := NewKey(string(msg.Key), w)
+
+		acc, _, err := a.opts.Store.Get(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		if err := a.opts.Store.Put(ctx, k, a.opts.Aggregate(acc, msg)); err != nil {
+			return err
+		}
+	}
+
+	p := partition{topic: msg.Topic, partition: msg.Partition}
+	if seen, ok := a.maxEvents[p]; !ok || t.After(seen) {
+		a.maxEvents[p] = t
+	}
+
+	if late && a.opts.OnLate != nil {
+		a.opts.OnLate(msg)
+	}
+
+	return nil
+}
+
+// advance moves the watermark to the lowest of the partition maxima,
+// minus the allowed lateness. The watermark never moves back.
+func (a *Aggregator[A]) advance() {
+	var low time.Time
+
+	for _, t := range a.maxEvents {
+		if low.IsZero() || t.Before(low) {
+			low = t
+		}
+	}
+
+	if low.IsZero() {
+		return
+	}
+
+	if w := low.Add(-a.opts.AllowedLateness); w.After(a.watermark) {
+		a.watermark = w
+	}
+}
+
+// emit emits the windows closed by the watermark, oldest first.
+// Windows are deleted only after they are emitted, so a failed
+// emit is retried with the next batch.
+func (a *Aggregator[A]) emit(ctx context.Context, all bool) error {
+	keys, err := a.opts.Store.Keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var closed []Key
+
+	for _, k := range keys {
+		if all || !k.Window().End.After(a.watermark) {
+			closed = append(closed, k)
+		}
+	}
+
+	slices.SortFunc(closed, func(x, y Key) int {
+		if c := cmp.Compare(x.End, y.End); c != 0 {
+			return c
+		}
+
+		if c := cmp.Compare(x.Start, y.Start); c != 0 {
+			return c
+		}
+
+		return strings.Compare(x.Key, y.Key)
+	})
+
+	for _, k := range closed {
+		value, ok, err := a.opts.Store.Get(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := a.opts.Emit(ctx, Result[A]{Key: k.Key, Window: k.Window(), Value: value}); err != nil {
+			return err
+		}
+
+		if err := a.opts.Store.Delete(ctx, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count is an AggregateFunc that counts the messages in a window.
+func Count(acc int, _ *xkafka.Message) int {
+	return acc + 1
+}
+
+// ToProducer returns an EmitFunc that publishes the results to the topic,
+// keyed by the message key. The value is encoded with encode.
+func ToProducer[A any](
+	producer *xkafka.Producer,
+	topic string,
+	encode func(r Result[A]) ([]byte, error),
+) EmitFunc[A] {
+	return func(ctx context.Context, r Result[A]) error {
+		value, err := encode(r)
+		if err != nil {
+			return err
+		}
+
+		return producer.Publish(ctx, &xkafka.Message{
+			Topic: topic,
+			Key:   []byte(r.Key),
+			Value: value,
+		})
+	}
+}
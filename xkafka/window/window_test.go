@@ -0,0 +1,294 @@
+package window
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) time.Time { return epoch.Add(d) }
+
+func newBatch(msgs ...*xkafka.Message) *xkafka.Batch {
+	b := xkafka.NewBatch()
+	b.Messages = msgs
+
+	return b
+}
+
+func newMsg(key string, d time.Duration) *xkafka.Message {
+	return &xkafka.Message{Key: []byte(key), Timestamp: at(d)}
+}
+
+func TestTumbling(t *testing.T) {
+	windows := Tumbling(time.Minute).Assign(at(90 * time.Second))
+
+	assert.Equal(t, []Window{{Start: at(time.Minute), End: at(2 * time.Minute)}}, windows)
+}
+
+func TestSliding(t *testing.T) {
+	windows := Sliding(time.Minute, 20*time.Second).Assign(at(50 * time.Second))
+
+	assert.Equal(t, []Window{
+		{Start: at(40 * time.Second), End: at(100 * time.Second)},
+		{Start: at(20 * time.Second), End: at(80 * time.Second)},
+		{Start: at(0), End: at(60 * time.Second)},
+	}, windows)
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	_, err := New(Options[int]{Assigner: Tumbling(time.Minute)})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestAggregatorTumbling(t *testing.T) {
+	var results []Result[int]
+
+	agg, err := New(Options[int]{
+		Assigner:  Tumbling(time.Minute),
+		Aggregate: Count,
+		Emit: func(_ context.Context, r Result[int]) error {
+			results = append(results, r)
+
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	batch := newBatch(
+		newMsg("a", 10*time.Second),
+		newMsg("b", 20*time.Second),
+		newMsg("a", 30*time.Second),
+	)
+
+	err = agg.HandleBatch(context.Background(), batch)
+	require.NoError(t, err)
+	assert.Equal(t, xkafka.Success, batch.Status)
+	assert.Empty(t, results)
+
+	err = agg.HandleBatch(context.Background(), newBatch(newMsg("a", 70*time.Second)))
+	require.NoError(t, err)
+
+	first := Window{Start: at(0), End: at(time.Minute)}
+	assert.Equal(t, []Result[int]{
+		{Key: "a", Window: first, Value: 2},
+		{Key: "b", Window: first, Value: 1},
+	}, results)
+
+	err = agg.Flush(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, Result[int]{
+		Key:    "a",
+		Window: Window{Start: at(time.Minute), End: at(2 * time.Minute)},
+		Value:  1,
+	}, results[2])
+}
+
+func TestAggregatorAllowedLateness(t *testing.T) {
+	var (
+		results []Result[int]
+		late    []*xkafka.Message
+	)
+
+	agg, err := New(Options[int]{
+		Assigner:        Tumbling(time.Minute),
+		Aggregate:       Count,
+		AllowedLateness: 30 * time.Second,
+		Emit: func(_ context.Context, r Result[int]) error {
+			results = append(results, r)
+
+			return nil
+		},
+		OnLate: func(msg *xkafka.Message) {
+			late = append(late, msg)
+		},
+	})
+	require.NoError(t, err)
+
+	err = agg.HandleBatch(context.Background(), newBatch(
+		newMsg("a", 10*time.Second),
+		newMsg("a", 80*time.Second),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, at(50*time.Second), agg.Watermark())
+	assert.Empty(t, results)
+
+	// within allowed lateness
+	err = agg.HandleBatch(context.Background(), newBatch(
+		newMsg("a", 55*time.Second),
+		newMsg("a", 100*time.Second),
+	))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Value)
+
+	// window already closed
+	tooLate := newMsg("a", 20*time.Second)
+
+	err = agg.HandleBatch(context.Background(), newBatch(tooLate))
+	require.NoError(t, err)
+	assert.Equal(t, []*xkafka.Message{tooLate}, late)
+	assert.Len(t, results, 1)
+}
+
+func TestAggregatorPartitions(t *testing.T) {
+	var (
+		results []Result[int]
+		late    []*xkafka.Message
+	)
+
+	agg, err := New(Options[int]{
+		Assigner:  Tumbling(time.Minute),
+		Aggregate: Count,
+		Emit: func(_ context.Context, r Result[int]) error {
+			results = append(results, r)
+
+			return nil
+		},
+		OnLate: func(msg *xkafka.Message) {
+			late = append(late, msg)
+		},
+	})
+	require.NoError(t, err)
+
+	onPartition := func(msg *xkafka.Message, p int32) *xkafka.Message {
+		msg.Topic = "events"
+		msg.Partition = p
+
+		return msg
+	}
+
+	// out of order within the batch
+	err = agg.HandleBatch(context.Background(), newBatch(
+		onPartition(newMsg("a", 70*time.Second), 0),
+		onPartition(newMsg("a", 10*time.Second), 0),
+		onPartition(newMsg("a", 20*time.Second), 1),
+	))
+	require.NoError(t, err)
+	assert.Empty(t, late)
+	assert.Equal(t, at(20*time.Second), agg.Watermark())
+	assert.Empty(t, results)
+
+	// partition 0 runs ahead, partition 1 holds the watermark back
+	err = agg.HandleBatch(context.Background(), newBatch(
+		onPartition(newMsg("a", 200*time.Second), 0),
+		onPartition(newMsg("a", 30*time.Second), 1),
+	))
+	require.NoError(t, err)
+	assert.Empty(t, late)
+	assert.Equal(t, at(30*time.Second), agg.Watermark())
+	assert.Empty(t, results)
+
+	err = agg.HandleBatch(context.Background(), newBatch(
+		onPartition(newMsg("a", 90*time.Second), 1),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, at(90*time.Second), agg.Watermark())
+	assert.Equal(t, []Result[int]{
+		{Key: "a", Window: Window{Start: at(0), End: at(time.Minute)}, Value: 3},
+	}, results)
+}
+
+func TestAggregatorEmitError(t *testing.T) {
+	store := NewMemoryStore[int]()
+	fail := true
+
+	agg, err := New(Options[int]{
+		Assigner:  Tumbling(time.Minute),
+		Aggregate: Count,
+		Store:     store,
+		Emit: func(_ context.Context, _ Result[int]) error {
+			if fail {
+				return assert.AnError
+			}
+
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	batch := newBatch(newMsg("a", 10*time.Second), newMsg("a", 70*time.Second))
+
+	err = agg.HandleBatch(context.Background(), batch)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, xkafka.Fail, batch.Status)
+	assert.Len(t, keys(t, store), 2)
+
+	fail = false
+
+	err = agg.HandleBatch(context.Background(), newBatch())
+	require.NoError(t, err)
+	assert.Len(t, keys(t, store), 1)
+}
+
+func keys[A any](t *testing.T, s Store[A]) []Key {
+	t.Helper()
+
+	k, err := s.Keys(context.Background())
+	require.NoError(t, err)
+
+	return k
+}
+
+type errStore struct {
+	*MemoryStore[int]
+}
+
+func (errStore) Put(context.Context, Key, int) error { return assert.AnError }
+
+func TestAggregatorStoreError(t *testing.T) {
+	agg, err := New(Options[int]{
+		Assigner:  Tumbling(time.Minute),
+		Aggregate: Count,
+		Store:     errStore{NewMemoryStore[int]()},
+		Emit:      func(context.Context, Result[int]) error { return nil },
+	})
+	require.NoError(t, err)
+
+	batch := newBatch(newMsg("a", 10*time.Second))
+
+	err = agg.HandleBatch(context.Background(), batch)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, xkafka.Fail, batch.Status)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore[int]()
+	k := NewKey("a", Window{Start: at(0), End: at(time.Minute)})
+
+	_, ok, err := s.Get(ctx, k)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Put(ctx, k, 1))
+
+	// the same instants in another location are the same key
+	local := time.FixedZone("IST", 5*60*60+30*60)
+	v, ok, err := s.Get(ctx, NewKey("a", Window{Start: at(0).In(local), End: at(time.Minute).In(local)}))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []Key{k}, keys(t, s))
+	assert.Equal(t, Window{Start: at(0), End: at(time.Minute)}, k.Window())
+
+	require.NoError(t, s.Delete(ctx, k))
+	assert.Empty(t, keys(t, s))
+}
+
+func TestAssignerInvalidSize(t *testing.T) {
+	assert.Panics(t, func() { Tumbling(0) })
+	assert.Panics(t, func() { Tumbling(-time.Minute) })
+	assert.Panics(t, func() { Sliding(time.Minute, 0) })
+	assert.Panics(t, func() { Sliding(time.Minute, -time.Second) })
+	assert.Panics(t, func() { Sliding(time.Minute, 2*time.Minute) })
+	assert.NotPanics(t, func() { Sliding(time.Minute, time.Minute) })
+}
@@ -39,9 +39,16 @@ type Options struct {
 	// VersionPath is the path for the version endpoint.
 	// If not provided, the default value is "version".
 	//
-	// Note: Prefix, HealthPath, ReadyPath, and VersionPath are only used
-	// for internal http.ServeMux registration.
-	Prefix, HealthPath, ReadyPath, VersionPath string
+	// MetricsPath is the path for the metrics endpoint.
+	// If not provided, the default value is "metrics".
+	//
+	// Note: Prefix, HealthPath, ReadyPath, VersionPath, and MetricsPath are
+	// only used for internal http.ServeMux registration.
+	Prefix, HealthPath, ReadyPath, VersionPath, MetricsPath string
+
+	// MetricsHandler is served at the metrics endpoint, if provided.
+	// For example, the Prometheus handler returned by xprom.Handler.
+	MetricsHandler http.Handler
 
 	ErrorLogDelegate func(string, map[string]any)
 
@@ -69,6 +76,7 @@ type ProbeHandler struct {
 	hh             http.Handler
 	rh             http.Handler
 	vh             http.Handler
+	mh             http.Handler
 	showErrReasons bool
 	logDelegate    func(string, map[string]any)
 }
@@ -85,6 +93,10 @@ func (h *ProbeHandler) ReadyHandler() http.Handler { return h.rh }
 // Note: It will be nil if the Options.BuildInfo is not provided.
 func (h *ProbeHandler) VersionHandler() http.Handler { return h.vh }
 
+// MetricsHandler returns the handler for the metrics endpoint.
+// Note: It will be nil if the Options.MetricsHandler is not provided.
+func (h *ProbeHandler) MetricsHandler() http.Handler { return h.mh }
+
 func (h *ProbeHandler) registerRoutes(opts Options) {
 	prefix := strings.TrimSuffix(opts.Prefix, "/")
 
@@ -104,6 +116,13 @@ func (h *ProbeHandler) registerRoutes(opts Options) {
 			h.vh.ServeHTTP,
 		)
 	}
+
+	if h.mh != nil {
+		h.sm.Handle(
+			fmt.Sprintf("%s/%s", prefix, pathOrDefault(opts.MetricsPath, "metrics")),
+			h.mh,
+		)
+	}
 }
 
 func pathOrDefault(path, def string) string {
@@ -222,6 +241,8 @@ func (h *ProbeHandler) makeHandlers(opts Options) {
 	if opts.BuildInfo != nil {
 		h.vh = h.versionHandler(opts)
 	}
+
+	h.mh = opts.MetricsHandler
 }
 
 type checkFailure struct {
@@ -315,3 +315,52 @@ func Test_pathOrDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestProbeHandler_serveMetrics(t *testing.T) {
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "build_info 1\n")
+	})
+
+	tests := []struct {
+		name     string
+		opts     Options
+		path     string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "NoMetricsHandler",
+			path:     "/metrics",
+			wantCode: http.StatusNotFound,
+			want:     "404 page not found\n",
+		},
+		{
+			name:     "DefaultPath",
+			opts:     Options{MetricsHandler: metrics},
+			path:     "/metrics",
+			wantCode: http.StatusOK,
+			want:     "build_info 1\n",
+		},
+		{
+			name:     "PrefixAndPath",
+			opts:     Options{Prefix: "/probe", MetricsPath: "/prometheus", MetricsHandler: metrics},
+			path:     "/probe/prometheus",
+			wantCode: http.StatusOK,
+			want:     "build_info 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rw := httptest.NewRecorder()
+
+			ph := NewProbeHandler(tt.opts)
+			ph.ServeHTTP(rw, req)
+
+			assert.Equal(t, tt.wantCode, rw.Code)
+			assert.Equal(t, tt.want, rw.Body.String())
+			assert.Equal(t, tt.opts.MetricsHandler != nil, ph.MetricsHandler() != nil)
+		})
+	}
+}
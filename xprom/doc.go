@@ -1,3 +1,7 @@
 // Package xprom provides a prometheus metrics that follow
 // the OpenTelemetry semantic conventions.
+//
+// RegisterRuntime registers the Go runtime and process collectors, and a
+// `build_info` gauge from xpod.BuildInfo. Handler serves the metrics, and
+// can be mounted on xpod.ProbeHandler next to the health endpoints.
 package xprom
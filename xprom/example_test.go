@@ -0,0 +1,30 @@
+package xprom_test
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gojekfarm/xtools/xpod"
+	"github.com/gojekfarm/xtools/xprom"
+)
+
+func ExampleRegisterRuntime() {
+	bi := &xpod.BuildInfo{
+		Version: "0.1.0",
+		Tag:     "v0.1.0",
+		Commit:  "24b3f5d876ffa402287bfa5c26cf05626a2b3b01",
+	}
+
+	reg := prometheus.NewRegistry()
+	_ = xprom.RegisterRuntime(reg, bi)
+
+	// Serve /probe/healthz, /probe/readyz, /probe/version and /probe/metrics.
+	ph := xpod.NewProbeHandler(xpod.Options{
+		Prefix:         "/probe",
+		BuildInfo:      bi,
+		MetricsHandler: xprom.Handler(reg),
+	})
+
+	_ = http.ListenAndServe(":8080", ph)
+}
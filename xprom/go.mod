@@ -1,3 +1,32 @@
 module github.com/gojekfarm/xtools/xprom
 
 go 1.25
+
+replace (
+	github.com/gojekfarm/xtools/generic => ../generic
+	github.com/gojekfarm/xtools/xpod => ../xpod
+	github.com/gojekfarm/xtools/xprom/semconv => ./semconv
+)
+
+require (
+	github.com/gojekfarm/xtools/xpod v0.10.0
+	github.com/gojekfarm/xtools/xprom/semconv v0.10.0
+	github.com/prometheus/client_golang v1.14.0
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gojekfarm/xtools/generic v0.10.0 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_model v0.3.0 // indirect
+	github.com/prometheus/common v0.37.0 // indirect
+	github.com/prometheus/procfs v0.8.0 // indirect
+	golang.org/x/sys v0.0.0-20220829200755-d48e67d00261 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,17 @@
+package xprom
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler returns a http.Handler that serves the metrics of the gatherer,
+// in the OpenMetrics format when requested, so that exemplars are exposed.
+// It can be mounted with xpod.Options.MetricsHandler.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
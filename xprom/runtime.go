@@ -0,0 +1,48 @@
+package xprom
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/gojekfarm/xtools/xpod"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+// RegisterRuntime registers the Go runtime and process collectors, and
+// the `build_info` gauge when bi is not nil, with the provided registry.
+func RegisterRuntime(registry prometheus.Registerer, bi *xpod.BuildInfo) error {
+	cs := []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}
+
+	if bi != nil {
+		cs = append(cs, NewBuildInfoCollector(bi))
+	}
+
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewBuildInfoCollector returns a collector for the `build_info` gauge,
+// which is always 1 and labelled with the version, tag and commit of
+// the build, and the Go version.
+func NewBuildInfoCollector(bi *xpod.BuildInfo) prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: semconv.BuildInfo,
+		Help: "Build information, with a constant value of 1.",
+		ConstLabels: prometheus.Labels{
+			semconv.BuildInfoVersion:   bi.Version,
+			semconv.BuildInfoTag:       bi.Tag,
+			semconv.BuildInfoCommit:    bi.Commit,
+			semconv.BuildInfoGoVersion: runtime.Version(),
+		},
+	}, func() float64 { return 1 })
+}
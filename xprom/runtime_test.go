@@ -0,0 +1,81 @@
+package xprom
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xpod"
+)
+
+func TestRegisterRuntime(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	bi := &xpod.BuildInfo{
+		Version: "0.1.0",
+		Tag:     "v0.1.0",
+		Commit:  "24b3f5d876ffa402287bfa5c26cf05626a2b3b01",
+	}
+
+	require.NoError(t, RegisterRuntime(reg, bi))
+
+	expected := fmt.Sprintf(`
+	# HELP build_info Build information, with a constant value of 1.
+	# TYPE build_info gauge
+	build_info{commit="24b3f5d876ffa402287bfa5c26cf05626a2b3b01",go_version="%s",tag="v0.1.0",version="0.1.0"} 1
+	`, runtime.Version())
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "build_info")
+	assert.NoError(t, err)
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(mfs))
+	for _, mf := range mfs {
+		names = append(names, mf.GetName())
+	}
+
+	assert.Contains(t, names, "go_goroutines")
+
+	if runtime.GOOS == "linux" {
+		assert.Contains(t, names, "process_cpu_seconds_total")
+	}
+
+	assert.Error(t, RegisterRuntime(reg, nil), "collectors are already registered")
+}
+
+func TestRegisterRuntimeWithoutBuildInfo(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	require.NoError(t, RegisterRuntime(reg, nil))
+
+	count, err := testutil.GatherAndCount(reg, "build_info")
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, RegisterRuntime(reg, &xpod.BuildInfo{Version: "0.1.0"}))
+
+	ph := xpod.NewProbeHandler(xpod.Options{
+		Prefix:         "/probe",
+		MetricsHandler: Handler(reg),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/probe/metrics", nil)
+	rw := httptest.NewRecorder()
+
+	ph.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `build_info{commit="",go_version="`+runtime.Version())
+}
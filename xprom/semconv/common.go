@@ -10,3 +10,16 @@ const (
 const (
 	ErrorType = "error_type"
 )
+
+// ErrorTypeOther is used for errors that are not classified,
+// to limit the cardinality of the error type label.
+const ErrorTypeOther = "_OTHER"
+
+// Build info metric and labels.
+const (
+	BuildInfo          = "build_info"
+	BuildInfoVersion   = "version"
+	BuildInfoTag       = "tag"
+	BuildInfoCommit    = "commit"
+	BuildInfoGoVersion = "go_version"
+)
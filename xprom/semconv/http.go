@@ -0,0 +1,25 @@
+package semconv
+
+// HTTP metric names.
+// https://github.com/open-telemetry/semantic-conventions/blob/v1.26.0/docs/http/http-metrics.md
+const (
+	HTTPServerRequestDuration  = "http_server_request_duration"
+	HTTPServerActiveRequests   = "http_server_active_requests"
+	HTTPServerRequestBodySize  = "http_server_request_body_size"
+	HTTPServerResponseBodySize = "http_server_response_body_size"
+	HTTPClientRequestDuration  = "http_client_request_duration"
+	HTTPClientActiveRequests   = "http_client_active_requests"
+)
+
+// HTTP labels.
+// https://github.com/open-telemetry/semantic-conventions/blob/v1.26.0/docs/http/http-metrics.md
+const (
+	HTTPRequestMethod      = "http_request_method"
+	HTTPRoute              = "http_route"
+	HTTPResponseStatusCode = "http_response_status_code"
+	URLScheme              = "url_scheme"
+)
+
+// HTTPMethodOther is used for HTTP methods that are not known,
+// to limit the cardinality of the method label.
+const HTTPMethodOther = "_OTHER"
@@ -18,6 +18,42 @@ const (
 	MessagingInflightMessages = "messaging_inflight_messages"
 )
 
+// Kafka client statistics metrics, reported by librdkafka.
+const (
+	MessagingKafkaClientQueueMessages       = "messaging_kafka_client_queue_messages"
+	MessagingKafkaClientQueueBytes          = "messaging_kafka_client_queue_bytes"
+	MessagingKafkaClientTransmittedMessages = "messaging_kafka_client_transmitted_messages"
+	MessagingKafkaClientReceivedMessages    = "messaging_kafka_client_received_messages"
+	MessagingKafkaBrokerRTT                 = "messaging_kafka_broker_rtt"
+	MessagingKafkaBrokerOutbufRequests      = "messaging_kafka_broker_outbuf_requests"
+	MessagingKafkaBrokerWaitrespRequests    = "messaging_kafka_broker_waitresp_requests"
+	MessagingKafkaPartitionConsumerLag      = "messaging_kafka_partition_consumer_lag"
+	MessagingKafkaPartitionQueueMessages    = "messaging_kafka_partition_queue_messages"
+)
+
+// Kafka consumer group metrics.
+const (
+	MessagingKafkaConsumerLag                = "messaging_kafka_consumer_lag"
+	MessagingKafkaConsumerCommittedOffset    = "messaging_kafka_consumer_committed_offset"
+	MessagingKafkaConsumerAssignedPartitions = "messaging_kafka_consumer_assigned_partitions"
+	MessagingKafkaPartitionHighWatermark     = "messaging_kafka_partition_high_watermark"
+)
+
+// Kafka batch consumer metrics.
+const (
+	MessagingKafkaBatchMessages = "messaging_kafka_batch_messages"
+	MessagingKafkaBatchBytes    = "messaging_kafka_batch_bytes"
+	MessagingKafkaBatchDuration = "messaging_kafka_batch_duration"
+	MessagingKafkaBatchFlushes  = "messaging_kafka_batch_flushes"
+)
+
+// Kafka producer metrics.
+const (
+	MessagingKafkaProducerDeliveryDuration = "messaging_kafka_producer_delivery_duration"
+	MessagingKafkaProducerDeliveryFailures = "messaging_kafka_producer_delivery_failures"
+	MessagingKafkaProducerQueueMessages    = "messaging_kafka_producer_queue_messages"
+)
+
 // Labels.
 // https://github.com/open-telemetry/semantic-conventions/blob/v1.26.0/docs/messaging/messaging-metrics.md
 const (
@@ -26,14 +62,17 @@ const (
 	MessagingDestinationName        = "messaging_destination_name"
 	MessagingConsumerGroupName      = "messaging_consumer_group_name"
 	MessagingDestinationPartitionID = "messaging_destination_partition_id"
+	MessagingClientID               = "messaging_client_id"
 )
 
 // Kafka aliases.
 const (
-	MessagingKafkaTopic         = MessagingDestinationName
-	MessagingKafkaPartition     = MessagingDestinationPartitionID
-	MessagingKafkaConsumerGroup = MessagingConsumerGroupName
-	MessagingKafkaMessageStatus = "messaging_kafka_message_status"
+	MessagingKafkaTopic            = MessagingDestinationName
+	MessagingKafkaPartition        = MessagingDestinationPartitionID
+	MessagingKafkaConsumerGroup    = MessagingConsumerGroupName
+	MessagingKafkaMessageStatus    = "messaging_kafka_message_status"
+	MessagingKafkaBatchFlushReason = "messaging_kafka_batch_flush_reason"
+	MessagingKafkaErrorCode        = "messaging_kafka_error_code"
 )
 
 // Operation names.
@@ -0,0 +1,185 @@
+package xpromhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+// ClientCollector provides metrics for HTTP clients.
+type ClientCollector struct {
+	opts     options
+	duration *prometheus.HistogramVec
+	active   *prometheus.GaugeVec
+}
+
+// NewClientCollector creates a new ClientCollector.
+func NewClientCollector(opts ...Option) *ClientCollector {
+	o := options{
+		latencyBuckets: defaultLatencyBuckets,
+		errFn:          classifyError,
+	}
+
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	return &ClientCollector{
+		opts: o,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    semconv.HTTPClientRequestDuration,
+			Help:    "Duration of HTTP client requests.",
+			Buckets: o.latencyBuckets,
+		}, []string{
+			semconv.HTTPRequestMethod,
+			semconv.HTTPResponseStatusCode,
+			semconv.URLScheme,
+			semconv.ServerAddress,
+			semconv.ServerPort,
+			semconv.ErrorType,
+		}),
+		active: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: semconv.HTTPClientActiveRequests,
+			Help: "HTTP client requests currently in flight.",
+		}, []string{
+			semconv.HTTPRequestMethod,
+			semconv.URLScheme,
+			semconv.ServerAddress,
+			semconv.ServerPort,
+		}),
+	}
+}
+
+// Register registers the metrics with the provided registry.
+func (c *ClientCollector) Register(registry prometheus.Registerer) error {
+	if err := registry.Register(c.duration); err != nil {
+		return err
+	}
+
+	return registry.Register(c.active)
+}
+
+// RoundTripper returns an http.RoundTripper that instruments the requests
+// sent with next. If next is nil, http.DefaultTransport is used.
+// Options passed to this function will override the ClientCollector options.
+//
+// The duration is measured until the response headers are received.
+// Failed requests have `error_type` set to the classified transport error,
+// or to the status code for 4xx and 5xx responses.
+func (c *ClientCollector) RoundTripper(next http.RoundTripper, opts ...Option) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	rtopts := &options{
+		address: c.opts.address,
+		port:    c.opts.port,
+		errFn:   c.opts.errFn,
+	}
+
+	for _, opt := range opts {
+		opt.apply(rtopts)
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		start := time.Now()
+		labels := prometheus.Labels{
+			semconv.HTTPRequestMethod: method(r),
+			semconv.URLScheme:         r.URL.Scheme,
+			semconv.ServerAddress:     r.URL.Hostname(),
+			semconv.ServerPort:        port(r),
+		}
+
+		if rtopts.address != "" {
+			labels[semconv.ServerAddress] = rtopts.address
+		}
+
+		if rtopts.port != 0 {
+			labels[semconv.ServerPort] = strconv.Itoa(rtopts.port)
+		}
+
+		active := c.active.With(labels)
+
+		active.Inc()
+		defer active.Dec()
+
+		res, err := next.RoundTrip(r)
+
+		labels[semconv.HTTPResponseStatusCode] = ""
+		labels[semconv.ErrorType] = ""
+
+		switch {
+		case err != nil:
+			labels[semconv.ErrorType] = rtopts.errFn(err)
+		case res.StatusCode >= http.StatusBadRequest:
+			labels[semconv.HTTPResponseStatusCode] = strconv.Itoa(res.StatusCode)
+			labels[semconv.ErrorType] = labels[semconv.HTTPResponseStatusCode]
+		default:
+			labels[semconv.HTTPResponseStatusCode] = strconv.Itoa(res.StatusCode)
+		}
+
+		c.duration.With(labels).Observe(time.Since(start).Seconds())
+
+		return res, err
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// port returns the port of the request URL, or the default port of the scheme.
+func port(r *http.Request) string {
+	if p := r.URL.Port(); p != "" {
+		return p
+	}
+
+	switch r.URL.Scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+
+	return ""
+}
+
+func classifyError(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+
+	var nerr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &nerr) && nerr.Timeout()) {
+		return "timeout"
+	}
+
+	var (
+		dnsErr    *net.DNSError
+		certErr   *tls.CertificateVerificationError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+	)
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.Is(err, syscall.ECONNRESET):
+		return "connection_reset"
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return "tls"
+	}
+
+	return semconv.ErrorTypeOther
+}
@@ -0,0 +1,201 @@
+package xpromhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reg := prometheus.NewRegistry()
+	collector := NewClientCollector()
+
+	require.NoError(t, collector.Register(reg))
+
+	client := &http.Client{Transport: collector.RoundTripper(nil)}
+
+	for _, path := range []string{"/ok", "/ok", "/missing"} {
+		res, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+
+		_ = res.Body.Close()
+	}
+
+	host, port := serverHostPort(t, server)
+
+	expected := `
+	# HELP http_client_active_requests HTTP client requests currently in flight.
+	# TYPE http_client_active_requests gauge
+	http_client_active_requests{http_request_method="GET",server_address="` + host + `",server_port="` + port + `",url_scheme="http"} 0
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_client_active_requests")
+	assert.NoError(t, err)
+
+	counts := histogramCounts(t, reg, "http_client_request_duration")
+	assert.Equal(t, map[string]uint64{
+		"GET 200 ":    2,
+		"GET 404 404": 1,
+	}, counts)
+}
+
+func TestClientRoundTripperErrors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewClientCollector()
+
+	require.NoError(t, collector.Register(reg))
+
+	errTransport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+
+		return nil, r.Context().Err()
+	})
+
+	client := &http.Client{Transport: collector.RoundTripper(errTransport)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://example.com/users", nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	custom := collector.RoundTripper(
+		roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("refused")
+		}),
+		ErrorClassifier(func(err error) string { return "refused" }),
+	)
+
+	_, err = custom.RoundTrip(httptest.NewRequest(http.MethodPost, "https://example.com/users", nil))
+	assert.Error(t, err)
+
+	counts := histogramCounts(t, reg, "http_client_request_duration")
+	assert.Equal(t, map[string]uint64{
+		"POST  timeout": 1,
+		"POST  refused": 1,
+	}, counts)
+}
+
+func TestClientRoundTripperOptions(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewClientCollector(Address("users-service"))
+
+	require.NoError(t, collector.Register(reg))
+
+	rt := collector.RoundTripper(
+		roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("refused")
+		}),
+		Port(8080),
+		ErrorClassifier(nil),
+	)
+
+	_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://10.0.0.1/users", nil))
+	assert.Error(t, err)
+
+	expected := `
+	# HELP http_client_active_requests HTTP client requests currently in flight.
+	# TYPE http_client_active_requests gauge
+	http_client_active_requests{http_request_method="GET",server_address="users-service",server_port="8080",url_scheme="https"} 0
+	`
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_client_active_requests")
+	assert.NoError(t, err)
+
+	// nil classifier falls back to the default
+	counts := histogramCounts(t, reg, "http_client_request_duration")
+	assert.Equal(t, map[string]uint64{"GET  _OTHER": 1}, counts)
+}
+
+func TestClassifyError(t *testing.T) {
+	testcases := []struct {
+		err      error
+		expected string
+	}{
+		{err: context.Canceled, expected: "canceled"},
+		{err: context.DeadlineExceeded, expected: "timeout"},
+		{err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}, expected: "dns"},
+		{err: &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, expected: "timeout"},
+		{err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, expected: "connection_refused"},
+		{err: fmt.Errorf("read: %w", syscall.ECONNRESET), expected: "connection_reset"},
+		{err: &tls.CertificateVerificationError{Err: errors.New("unknown authority")}, expected: "tls"},
+		{err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, expected: "tls"},
+		{err: errors.New("unexpected"), expected: "_OTHER"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, classifyError(tc.err))
+		})
+	}
+}
+
+// histogramCounts returns the sample counts of a histogram by method,
+// status code and error type.
+func histogramCounts(t *testing.T, reg *prometheus.Registry, name string) map[string]uint64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	counts := map[string]uint64{}
+
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+
+			key := strings.Join([]string{
+				labels["http_request_method"],
+				labels["http_response_status_code"],
+				labels["error_type"],
+			}, " ")
+
+			counts[key] = m.GetHistogram().GetSampleCount()
+		}
+	}
+
+	return counts
+}
+
+func serverHostPort(t *testing.T, server *httptest.Server) (string, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	return req.URL.Hostname(), req.URL.Port()
+}
@@ -0,0 +1,217 @@
+package xpromhttp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gojekfarm/xtools/xapi"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+var (
+	defaultLatencyBuckets = []float64{
+		0.005, // 5ms
+		0.01,  // 10ms
+		0.025, // 25ms
+		0.05,  // 50ms
+		0.075, // 75ms
+		0.1,   // 100ms
+		0.25,  // 250ms
+		0.5,   // 500ms
+		0.75,  // 750ms
+		1,     // 1s
+		2.5,   // 2.5s
+		5,     // 5s
+		7.5,   // 7.5s
+		10,    // 10s
+	}
+
+	defaultSizeBuckets = []float64{
+		100,      // 100B
+		1000,     // 1KB
+		10000,    // 10KB
+		100000,   // 100KB
+		1000000,  // 1MB
+		10000000, // 10MB
+	}
+
+	knownMethods = map[string]struct{}{
+		http.MethodGet:     {},
+		http.MethodHead:    {},
+		http.MethodPost:    {},
+		http.MethodPut:     {},
+		http.MethodPatch:   {},
+		http.MethodDelete:  {},
+		http.MethodConnect: {},
+		http.MethodOptions: {},
+		http.MethodTrace:   {},
+	}
+)
+
+// Collector provides metrics for HTTP servers.
+type Collector struct {
+	opts         options
+	duration     *prometheus.HistogramVec
+	active       *prometheus.GaugeVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// NewCollector creates a new Collector.
+func NewCollector(opts ...Option) *Collector {
+	o := options{
+		latencyBuckets: defaultLatencyBuckets,
+		sizeBuckets:    defaultSizeBuckets,
+	}
+
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	requestLabels := []string{
+		semconv.HTTPRequestMethod,
+		semconv.HTTPRoute,
+		semconv.HTTPResponseStatusCode,
+		semconv.URLScheme,
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.ErrorType,
+	}
+
+	return &Collector{
+		opts: o,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    semconv.HTTPServerRequestDuration,
+			Help:    "Duration of HTTP server requests.",
+			Buckets: o.latencyBuckets,
+		}, requestLabels),
+		active: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: semconv.HTTPServerActiveRequests,
+			Help: "HTTP server requests currently being processed.",
+		}, []string{
+			semconv.HTTPRequestMethod,
+			semconv.URLScheme,
+			semconv.ServerAddress,
+			semconv.ServerPort,
+		}),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    semconv.HTTPServerRequestBodySize,
+			Help:    "Size of HTTP server request bodies.",
+			Buckets: o.sizeBuckets,
+		}, requestLabels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    semconv.HTTPServerResponseBodySize,
+			Help:    "Size of HTTP server response bodies.",
+			Buckets: o.sizeBuckets,
+		}, requestLabels),
+	}
+}
+
+// Register registers the metrics with the provided registry.
+func (c *Collector) Register(registry prometheus.Registerer) error {
+	for _, m := range []prometheus.Collector{c.duration, c.active, c.requestSize, c.responseSize} {
+		if err := registry.Register(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Middleware returns a middleware that instruments xapi endpoints and
+// http.Handler. Options passed to this function will override the
+// Collector options.
+//
+// The `http_route` label is set with the Route option, or read from the
+// pattern matched by http.ServeMux, when the middleware wraps the mux.
+func (c *Collector) Middleware(opts ...Option) xapi.MiddlewareFunc {
+	mwopts := &options{
+		address: c.opts.address,
+		port:    c.opts.port,
+		route:   c.opts.route,
+	}
+
+	for _, opt := range opts {
+		opt.apply(mwopts)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			labels := prometheus.Labels{
+				semconv.HTTPRequestMethod: method(r),
+				semconv.URLScheme:         scheme(r),
+				semconv.ServerAddress:     mwopts.address,
+				semconv.ServerPort:        "",
+			}
+
+			if mwopts.port != 0 {
+				labels[semconv.ServerPort] = strconv.Itoa(mwopts.port)
+			}
+
+			active := c.active.With(labels)
+
+			active.Inc()
+			defer active.Dec()
+
+			body := &requestBody{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			labels[semconv.HTTPRoute] = mwopts.route
+			if labels[semconv.HTTPRoute] == "" {
+				labels[semconv.HTTPRoute] = route(r.Pattern)
+			}
+
+			labels[semconv.HTTPResponseStatusCode] = strconv.Itoa(rw.status)
+			labels[semconv.ErrorType] = ""
+
+			if rw.status >= http.StatusInternalServerError {
+				labels[semconv.ErrorType] = strconv.Itoa(rw.status)
+			}
+
+			c.duration.With(labels).Observe(time.Since(start).Seconds())
+			c.requestSize.With(labels).Observe(float64(body.size))
+			c.responseSize.With(labels).Observe(float64(rw.size))
+		})
+	}
+}
+
+// method returns the request method, or `_OTHER` for unknown methods.
+func method(r *http.Request) string {
+	if _, ok := knownMethods[r.Method]; ok {
+		return r.Method
+	}
+
+	return semconv.HTTPMethodOther
+}
+
+func scheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// route returns the path of a http.ServeMux pattern, `[METHOD ][HOST]/[PATH]`.
+func route(pattern string) string {
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimLeft(path, " \t")
+	}
+
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:]
+	}
+
+	return pattern
+}
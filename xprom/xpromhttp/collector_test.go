@@ -0,0 +1,134 @@
+package xpromhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xapi"
+)
+
+type echoRequest struct {
+	Name string `json:"name"`
+}
+
+type echoResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func TestMiddlewareEndpoint(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(
+		LatencyBuckets{0.1, 1},
+		SizeBuckets{10, 100},
+		Address("localhost"),
+		Port(8080),
+	)
+
+	require.NoError(t, collector.Register(reg))
+
+	endpoint := xapi.NewEndpoint(
+		xapi.EndpointFunc[echoRequest, echoResponse](func(ctx context.Context, req *echoRequest) (*echoResponse, error) {
+			return &echoResponse{Greeting: "hello " + req.Name}, nil
+		}),
+		xapi.WithMiddleware(collector.Middleware(Route("/echo"))),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"name":"xapi"}`))
+	rec := httptest.NewRecorder()
+
+	endpoint.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	expected := `
+	# HELP http_server_active_requests HTTP server requests currently being processed.
+	# TYPE http_server_active_requests gauge
+	http_server_active_requests{http_request_method="POST",server_address="localhost",server_port="8080",url_scheme="http"} 0
+	# HELP http_server_request_body_size Size of HTTP server request bodies.
+	# TYPE http_server_request_body_size histogram
+	http_server_request_body_size_bucket{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http",le="10"} 0
+	http_server_request_body_size_bucket{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http",le="100"} 1
+	http_server_request_body_size_bucket{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http",le="+Inf"} 1
+	http_server_request_body_size_sum{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http"} 15
+	http_server_request_body_size_count{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http"} 1
+	# HELP http_server_response_body_size Size of HTTP server response bodies.
+	# TYPE http_server_response_body_size histogram
+	http_server_response_body_size_bucket{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http",le="10"} 0
+	http_server_response_body_size_bucket{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http",le="100"} 1
+	http_server_response_body_size_bucket{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http",le="+Inf"} 1
+	http_server_response_body_size_sum{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http"} 25
+	http_server_response_body_size_count{error_type="",http_request_method="POST",http_response_status_code="200",http_route="/echo",server_address="localhost",server_port="8080",url_scheme="http"} 1
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"http_server_active_requests",
+		"http_server_request_body_size",
+		"http_server_response_body_size",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.duration))
+}
+
+func TestMiddlewareServeMux(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(SizeBuckets{100})
+
+	require.NoError(t, collector.Register(reg))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+
+	handler := collector.Middleware()(mux)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "https://example.com/users/2", nil),
+		httptest.NewRequest("PURGE", "/users/1", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+	# HELP http_server_response_body_size Size of HTTP server response bodies.
+	# TYPE http_server_response_body_size histogram
+	http_server_response_body_size_bucket{error_type="",http_request_method="_OTHER",http_response_status_code="405",http_route="",server_address="",server_port="",url_scheme="http",le="100"} 1
+	http_server_response_body_size_bucket{error_type="",http_request_method="_OTHER",http_response_status_code="405",http_route="",server_address="",server_port="",url_scheme="http",le="+Inf"} 1
+	http_server_response_body_size_sum{error_type="",http_request_method="_OTHER",http_response_status_code="405",http_route="",server_address="",server_port="",url_scheme="http"} 19
+	http_server_response_body_size_count{error_type="",http_request_method="_OTHER",http_response_status_code="405",http_route="",server_address="",server_port="",url_scheme="http"} 1
+	http_server_response_body_size_bucket{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="http",le="100"} 1
+	http_server_response_body_size_bucket{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="http",le="+Inf"} 1
+	http_server_response_body_size_sum{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="http"} 12
+	http_server_response_body_size_count{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="http"} 1
+	http_server_response_body_size_bucket{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="https",le="100"} 1
+	http_server_response_body_size_bucket{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="https",le="+Inf"} 1
+	http_server_response_body_size_sum{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="https"} 12
+	http_server_response_body_size_count{error_type="503",http_request_method="GET",http_response_status_code="503",http_route="/users/{id}",server_address="",server_port="",url_scheme="https"} 1
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_server_response_body_size")
+	assert.NoError(t, err)
+}
+
+func TestRoute(t *testing.T) {
+	testcases := map[string]string{
+		"":                           "",
+		"/":                          "/",
+		"/users/{id}":                "/users/{id}",
+		"GET /users/{id}":            "/users/{id}",
+		"example.com/users/{id}":     "/users/{id}",
+		"POST example.com/users/{$}": "/users/{$}",
+	}
+
+	for pattern, expect := range testcases {
+		assert.Equal(t, expect, route(pattern), pattern)
+	}
+}
@@ -0,0 +1,6 @@
+// Package xpromhttp provides Prometheus metrics for HTTP servers and clients.
+// The metrics and labels follow the OpenTelemetry semantic conventions for HTTP.
+//
+// Collector instruments servers, as a middleware for xapi endpoints and
+// net/http handlers. ClientCollector instruments clients, as an http.RoundTripper.
+package xpromhttp
@@ -0,0 +1,79 @@
+package xpromhttp_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/gojekfarm/xtools/xapi"
+	"github.com/gojekfarm/xtools/xprom/xpromhttp"
+)
+
+type GetUserRequest struct {
+	ID string `json:"-"`
+}
+
+type GetUserResponse struct {
+	Name string `json:"name"`
+}
+
+func ExampleCollector_Middleware() {
+	reg := prometheus.NewRegistry()
+	collector := xpromhttp.NewCollector(
+		xpromhttp.Address("localhost"),
+		xpromhttp.Port(8080),
+	)
+
+	_ = collector.Register(reg)
+
+	getUser := xapi.NewEndpoint(
+		xapi.EndpointFunc[GetUserRequest, GetUserResponse](
+			func(ctx context.Context, req *GetUserRequest) (*GetUserResponse, error) {
+				return &GetUserResponse{Name: "xtools"}, nil
+			},
+		),
+		// the route template of the endpoint
+		xapi.WithMiddleware(collector.Middleware(xpromhttp.Route("/users/{id}"))),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /users/{id}", getUser.Handler())
+	mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	_ = http.ListenAndServe(":8080", mux)
+}
+
+func ExampleCollector_Middleware_serveMux() {
+	reg := prometheus.NewRegistry()
+	collector := xpromhttp.NewCollector()
+
+	_ = collector.Register(reg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.PathValue("id")))
+	})
+
+	// the route is read from the pattern matched by the mux
+	_ = http.ListenAndServe(":8080", collector.Middleware()(mux))
+}
+
+func ExampleClientCollector_RoundTripper() {
+	reg := prometheus.NewRegistry()
+	collector := xpromhttp.NewClientCollector()
+
+	_ = collector.Register(reg)
+
+	client := &http.Client{
+		Transport: collector.RoundTripper(http.DefaultTransport),
+	}
+
+	res, err := client.Get("https://example.com")
+	if err != nil {
+		return
+	}
+
+	_ = res.Body.Close()
+}
@@ -0,0 +1,64 @@
+package xpromhttp
+
+// Option configures the collector.
+type Option interface {
+	apply(*options)
+}
+
+type optionFunc func(*options)
+
+func (f optionFunc) apply(o *options) { f(o) }
+
+type errorClassifier func(error) string
+
+type options struct {
+	latencyBuckets []float64
+	sizeBuckets    []float64
+	address        string
+	port           int
+	route          string
+	errFn          errorClassifier
+}
+
+// LatencyBuckets configures the request duration buckets, in seconds.
+type LatencyBuckets []float64
+
+func (l LatencyBuckets) apply(o *options) { o.latencyBuckets = l }
+
+// SizeBuckets configures the request and response body size buckets, in bytes.
+type SizeBuckets []float64
+
+func (s SizeBuckets) apply(o *options) { o.sizeBuckets = s }
+
+// Address sets `server_address` label. For the client RoundTripper,
+// it replaces the host of the request URL, for example with
+// the name of the upstream service.
+type Address string
+
+func (a Address) apply(o *options) { o.address = string(a) }
+
+// Port sets `server_port` label. For the client RoundTripper,
+// it replaces the port of the request URL.
+type Port int
+
+func (p Port) apply(o *options) { o.port = int(p) }
+
+// Route sets `http_route` label, the route template of the handler,
+// like `/users/{id}`. By default, the route is read from the pattern
+// matched by http.ServeMux.
+type Route string
+
+func (r Route) apply(o *options) { o.route = string(r) }
+
+// ErrorClassifier classifies the errors returned by the transport for
+// `error_type` label. By default, the error type is one of `timeout`,
+// `canceled`, `dns`, `connection_refused`, `connection_reset` and `tls`,
+// or `_OTHER` for the other errors.
+// A nil classifier is ignored.
+func ErrorClassifier(fn func(error) string) Option {
+	return optionFunc(func(o *options) {
+		if fn != nil {
+			o.errFn = fn
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package xpromhttp
+
+import (
+	"io"
+	"net/http"
+)
+
+// responseWriter records the status code and the body size of the response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	size        int64
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	// informational responses are followed by the final status
+	if !w.wroteHeader && code >= http.StatusOK {
+		w.status = code
+		w.wroteHeader = true
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Flush implements http.Flusher, for handlers that stream responses.
+func (w *responseWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap allows http.ResponseController to access the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// requestBody records the size of the request body read by the handler.
+type requestBody struct {
+	io.ReadCloser
+	size int64
+}
+
+func (b *requestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+
+	return n, err
+}
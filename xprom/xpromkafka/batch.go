@@ -0,0 +1,135 @@
+package xpromkafka
+
+import (
+	"context"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+var (
+	defaultBatchSizeBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+	defaultBatchBytesBuckets = []float64{
+		1 << 10,   // 1KB
+		4 << 10,   // 4KB
+		16 << 10,  // 16KB
+		64 << 10,  // 64KB
+		256 << 10, // 256KB
+		1 << 20,   // 1MB
+		4 << 20,   // 4MB
+		16 << 20,  // 16MB
+		64 << 20,  // 64MB
+	}
+)
+
+// BatchSizeBuckets configures the buckets of the batch size histogram,
+// in number of messages.
+type BatchSizeBuckets []float64
+
+func (b BatchSizeBuckets) apply(o *options) { o.batchSizeBuckets = b }
+
+// BatchBytesBuckets configures the buckets of the batch bytes histogram.
+type BatchBytesBuckets []float64
+
+func (b BatchBytesBuckets) apply(o *options) { o.batchBytesBuckets = b }
+
+// batchMetrics are recorded by the BatchConsumerMiddleware, once per batch.
+type batchMetrics struct {
+	flushLabels []string
+	opLabels    []string
+	messages    Histogram
+	bytes       Histogram
+	duration    Histogram
+	flushes     Counter
+}
+
+func newBatchMetrics(o options, constLabels Labels) *batchMetrics {
+	sizeBuckets, bytesBuckets := o.batchSizeBuckets, o.batchBytesBuckets
+
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = defaultBatchSizeBuckets
+	}
+
+	if len(bytesBuckets) == 0 {
+		bytesBuckets = defaultBatchBytesBuckets
+	}
+
+	flushLabels := o.filterLabels([]string{
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingKafkaConsumerGroup,
+		semconv.MessagingKafkaBatchFlushReason,
+	})
+	opLabels := o.filterLabels([]string{
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingKafkaConsumerGroup,
+		semconv.MessagingKafkaMessageStatus,
+		semconv.ErrorType,
+	})
+
+	return &batchMetrics{
+		flushLabels: flushLabels,
+		opLabels:    opLabels,
+		messages: o.meter.Histogram(InstrumentOpts{
+			Name:        semconv.MessagingKafkaBatchMessages,
+			Help:        "Messages in a consumed batch.",
+			Unit:        UnitMessages,
+			Labels:      flushLabels,
+			ConstLabels: constLabels,
+			Buckets:     sizeBuckets,
+		}),
+		bytes: o.meter.Histogram(InstrumentOpts{
+			Name:        semconv.MessagingKafkaBatchBytes,
+			Help:        "Size of the message keys and values in a consumed batch.",
+			Unit:        UnitBytes,
+			Labels:      flushLabels,
+			ConstLabels: constLabels,
+			Buckets:     bytesBuckets,
+		}),
+		duration: o.meter.Histogram(InstrumentOpts{
+			Name:        semconv.MessagingKafkaBatchDuration,
+			Help:        "Batch processing duration.",
+			Unit:        UnitSeconds,
+			Labels:      opLabels,
+			ConstLabels: constLabels,
+			Buckets:     o.durationBuckets(),
+		}),
+		flushes: o.meter.Counter(InstrumentOpts{
+			Name:        semconv.MessagingKafkaBatchFlushes,
+			Help:        "Batches sent to the handler, by flush reason.",
+			Unit:        UnitBatches,
+			Labels:      flushLabels,
+			ConstLabels: constLabels,
+		}),
+	}
+}
+
+func (m *batchMetrics) instruments() []any {
+	return []any{m.messages, m.bytes, m.duration, m.flushes}
+}
+
+// record records the batch. The labels must have the server and consumer
+// group labels set.
+func (m *batchMetrics) record(ctx context.Context, labels Labels, batch *xkafka.Batch, seconds float64) {
+	labels[semconv.MessagingKafkaBatchFlushReason] = batch.FlushReason.String()
+
+	flushLabels := pick(labels, m.flushLabels)
+
+	m.messages.Record(ctx, float64(len(batch.Messages)), flushLabels)
+	m.bytes.Record(ctx, float64(batchBytes(batch)), flushLabels)
+	m.flushes.Add(ctx, 1, flushLabels)
+
+	m.duration.Record(ctx, seconds, pick(labels, m.opLabels))
+}
+
+func batchBytes(batch *xkafka.Batch) int {
+	var size int
+
+	for _, msg := range batch.Messages {
+		size += len(msg.Key) + len(msg.Value)
+	}
+
+	return size
+}
@@ -0,0 +1,95 @@
+package xpromkafka
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+func TestBatchConsumerMiddlewareBatchMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(
+		LatencyBuckets{0.1, 1},
+		BatchSizeBuckets{1, 5},
+		BatchBytesBuckets{10, 100},
+		DropLabels("server_address", "server_port"),
+	)
+
+	require.NoError(t, collector.Register(reg))
+
+	handler := collector.BatchConsumerMiddleware().BatchMiddleware(
+		xkafka.BatchHandlerFunc(func(ctx context.Context, b *xkafka.Batch) error {
+			b.AckSuccess()
+
+			return nil
+		}),
+	)
+
+	for _, reason := range []xkafka.FlushReason{xkafka.FlushSize, xkafka.FlushTimeout, xkafka.FlushSize} {
+		batch := xkafka.NewBatch()
+		batch.FlushReason = reason
+
+		for range 2 {
+			batch.Messages = append(batch.Messages, &xkafka.Message{
+				Topic: "test-topic",
+				Group: "test-group",
+				Key:   []byte("key"),
+				Value: []byte("value"),
+			})
+		}
+
+		require.NoError(t, handler.HandleBatch(context.TODO(), batch))
+	}
+
+	// empty batches are not recorded
+	require.NoError(t, handler.HandleBatch(context.TODO(), xkafka.NewBatch()))
+
+	expected := `
+	# HELP messaging_kafka_batch_bytes Size of the message keys and values in a consumed batch.
+	# TYPE messaging_kafka_batch_bytes histogram
+	messaging_kafka_batch_bytes_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka",le="10"} 0
+	messaging_kafka_batch_bytes_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka",le="100"} 2
+	messaging_kafka_batch_bytes_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka",le="+Inf"} 2
+	messaging_kafka_batch_bytes_sum{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka"} 32
+	messaging_kafka_batch_bytes_count{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka"} 2
+	messaging_kafka_batch_bytes_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka",le="10"} 0
+	messaging_kafka_batch_bytes_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka",le="100"} 1
+	messaging_kafka_batch_bytes_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka",le="+Inf"} 1
+	messaging_kafka_batch_bytes_sum{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka"} 16
+	messaging_kafka_batch_bytes_count{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka"} 1
+	# HELP messaging_kafka_batch_flushes Batches sent to the handler, by flush reason.
+	# TYPE messaging_kafka_batch_flushes counter
+	messaging_kafka_batch_flushes{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka"} 2
+	messaging_kafka_batch_flushes{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka"} 1
+	# HELP messaging_kafka_batch_messages Messages in a consumed batch.
+	# TYPE messaging_kafka_batch_messages histogram
+	messaging_kafka_batch_messages_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka",le="1"} 0
+	messaging_kafka_batch_messages_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka",le="5"} 2
+	messaging_kafka_batch_messages_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka",le="+Inf"} 2
+	messaging_kafka_batch_messages_sum{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka"} 4
+	messaging_kafka_batch_messages_count{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="SIZE",messaging_system="kafka"} 2
+	messaging_kafka_batch_messages_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka",le="1"} 0
+	messaging_kafka_batch_messages_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka",le="5"} 1
+	messaging_kafka_batch_messages_bucket{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka",le="+Inf"} 1
+	messaging_kafka_batch_messages_sum{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka"} 2
+	messaging_kafka_batch_messages_count{messaging_consumer_group_name="test-group",messaging_kafka_batch_flush_reason="TIMEOUT",messaging_system="kafka"} 1
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"messaging_kafka_batch_bytes",
+		"messaging_kafka_batch_flushes",
+		"messaging_kafka_batch_messages",
+	)
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(reg, "messaging_kafka_batch_duration")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,95 @@
+package xpromkafka
+
+import (
+	"slices"
+	"sync"
+)
+
+// ErrorTypeOther is the `error_type` label value of the error types
+// that exceed MaxErrorTypes.
+const ErrorTypeOther = "other"
+
+// DropLabels removes the labels from the Collector metrics, which are
+// aggregated over the values of the dropped labels. For example,
+// dropping `messaging_destination_partition_id` reports one series per
+// topic instead of one per partition. Works only with NewCollector.
+func DropLabels(labels ...string) Option {
+	return optionFunc(func(o *options) {
+		o.dropLabels = append(o.dropLabels, labels...)
+	})
+}
+
+// AllowLabels keeps only the allowed labels on the Collector metrics.
+// The other labels are dropped, as with DropLabels. Works only with NewCollector.
+func AllowLabels(labels ...string) Option {
+	return optionFunc(func(o *options) {
+		o.allowLabels = append(o.allowLabels, labels...)
+	})
+}
+
+// MaxErrorTypes limits the number of distinct `error_type` values returned
+// by the ErrorClassifer. Once the limit is reached, new error types are
+// reported as ErrorTypeOther. Zero means no limit. The limit is shared
+// by the middlewares of a Collector, and works only with NewCollector.
+type MaxErrorTypes int
+
+func (m MaxErrorTypes) apply(o *options) { o.maxErrorTypes = int(m) }
+
+// filterLabels returns the labels that are not dropped.
+func (o *options) filterLabels(labels []string) []string {
+	return slices.DeleteFunc(labels, func(label string) bool {
+		if len(o.allowLabels) > 0 && !slices.Contains(o.allowLabels, label) {
+			return true
+		}
+
+		return slices.Contains(o.dropLabels, label)
+	})
+}
+
+// pick returns the values of the given labels.
+func pick(labels Labels, names []string) Labels {
+	picked := make(Labels, len(names))
+
+	for _, name := range names {
+		picked[name] = labels[name]
+	}
+
+	return picked
+}
+
+// errorTypes tracks the distinct error types, up to max.
+type errorTypes struct {
+	max  int
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newErrorTypes(maxTypes int) *errorTypes {
+	return &errorTypes{
+		max:  maxTypes,
+		seen: make(map[string]struct{}),
+	}
+}
+
+// limit returns the error type, or ErrorTypeOther if it is a new type
+// and the limit is reached.
+func (e *errorTypes) limit(errType string) string {
+	if e.max <= 0 || errType == "" {
+		return errType
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.seen[errType]; ok {
+		return errType
+	}
+
+	if len(e.seen) >= e.max {
+		return ErrorTypeOther
+	}
+
+	e.seen[errType] = struct{}{}
+
+	return errType
+}
@@ -0,0 +1,110 @@
+package xpromkafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+func TestCollectorDropLabels(t *testing.T) {
+	testcases := []struct {
+		name     string
+		options  []Option
+		expected string
+	}{
+		{
+			name:    "drop labels",
+			options: []Option{DropLabels(semconv.MessagingKafkaPartition, semconv.ServerPort)},
+			expected: `
+			# HELP messaging_client_consumed_messages Messages consumed.
+			# TYPE messaging_client_consumed_messages counter
+			messaging_client_consumed_messages{error_type="",messaging_consumer_group_name="test-group",messaging_destination_name="test-topic",messaging_kafka_message_status="SUCCESS",messaging_operation_name="consume",messaging_system="kafka",server_address=""} 3
+			`,
+		},
+		{
+			name:    "allow labels",
+			options: []Option{AllowLabels(semconv.MessagingKafkaTopic, semconv.ErrorType)},
+			expected: `
+			# HELP messaging_client_consumed_messages Messages consumed.
+			# TYPE messaging_client_consumed_messages counter
+			messaging_client_consumed_messages{error_type="",messaging_destination_name="test-topic",messaging_system="kafka"} 3
+			`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			collector := NewCollector(tc.options...)
+
+			require.NoError(t, collector.Register(reg))
+
+			handler := collector.ConsumerMiddleware().Middleware(
+				xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+					m.AckSuccess()
+
+					return nil
+				}),
+			)
+
+			for partition := range int32(3) {
+				msg := &xkafka.Message{Topic: "test-topic", Group: "test-group", Partition: partition}
+
+				require.NoError(t, handler.Handle(context.Background(), msg))
+			}
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(tc.expected), "messaging_client_consumed_messages")
+			assert.NoError(t, err)
+			count, err := testutil.GatherAndCount(reg, "messaging_inflight_messages")
+			assert.NoError(t, err)
+			assert.Equal(t, 1, count)
+		})
+	}
+}
+
+func TestCollectorMaxErrorTypes(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(
+		AllowLabels(semconv.ErrorType),
+		MaxErrorTypes(2),
+		ErrorClassifer(func(err error) string { return err.Error() }),
+	)
+
+	require.NoError(t, collector.Register(reg))
+
+	handler := collector.ConsumerMiddleware().Middleware(
+		xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+			err := errors.New(string(m.Value))
+
+			m.AckFail(err)
+
+			return err
+		}),
+	)
+
+	for _, errType := range []string{"a", "b", "c", "a", "d"} {
+		msg := &xkafka.Message{Topic: "test-topic", Value: []byte(errType)}
+
+		assert.Error(t, handler.Handle(context.Background(), msg))
+	}
+
+	expected := `
+	# HELP messaging_client_consumed_messages Messages consumed.
+	# TYPE messaging_client_consumed_messages counter
+	messaging_client_consumed_messages{error_type="a",messaging_system="kafka"} 2
+	messaging_client_consumed_messages{error_type="b",messaging_system="kafka"} 1
+	messaging_client_consumed_messages{error_type="other",messaging_system="kafka"} 2
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "messaging_client_consumed_messages")
+	assert.NoError(t, err)
+}
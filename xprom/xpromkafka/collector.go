@@ -11,6 +11,14 @@ import (
 	"github.com/gojekfarm/xtools/xprom/semconv"
 )
 
+const (
+	defaultNativeBucketFactor     = 1.1
+	defaultNativeMaxBucketNumber  = 160
+	defaultNativeMinResetDuration = time.Hour
+
+	exemplarTraceID = "trace_id"
+)
+
 var (
 	defaultLatencyBuckets = []float64{
 		0.001, // 1ms
@@ -31,103 +39,104 @@ var (
 
 // Collector provides metrics for xkafka.Producer and xkafka.Consumer.
 type Collector struct {
-	opts      options
-	duration  *prometheus.HistogramVec
-	inflight  *prometheus.GaugeVec
-	published *prometheus.CounterVec
-	consumed  *prometheus.CounterVec
+	opts           options
+	opLabels       []string
+	inflightLabels []string
+	errorTypes     *errorTypes
+	duration       Histogram
+	inflight       Counter
+	published      Counter
+	consumed       Counter
+	batch          *batchMetrics
+	delivery       *deliveryMetrics
 }
 
 // NewCollector creates a new Collector.
 func NewCollector(opts ...Option) *Collector {
-	o := options{
-		latencyBuckets: defaultLatencyBuckets,
-	}
+	o := options{}
 
 	for _, opt := range opts {
 		opt.apply(&o)
 	}
 
-	constLabels := prometheus.Labels{
+	if o.meter == nil {
+		o.meter = &promMeter{native: o.native}
+	}
+
+	constLabels := Labels{
 		semconv.MessagingSystem: semconv.SystemKafka,
 	}
 
+	opLabels := o.filterLabels([]string{
+		semconv.MessagingOperationName,
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingKafkaConsumerGroup,
+		semconv.MessagingKafkaTopic,
+		semconv.MessagingKafkaPartition,
+		semconv.MessagingKafkaMessageStatus,
+		semconv.ErrorType,
+	})
+	inflightLabels := o.filterLabels([]string{
+		semconv.MessagingOperationName,
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingKafkaConsumerGroup,
+		semconv.MessagingKafkaTopic,
+		semconv.MessagingKafkaPartition,
+	})
+
 	return &Collector{
-		opts: o,
-		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		opts:           o,
+		opLabels:       opLabels,
+		inflightLabels: inflightLabels,
+		errorTypes:     newErrorTypes(o.maxErrorTypes),
+		duration: o.meter.Histogram(InstrumentOpts{
 			Name:        semconv.MessagingClientOperationDuration,
 			Help:        "Message processing duration.",
+			Unit:        UnitSeconds,
+			Labels:      opLabels,
 			ConstLabels: constLabels,
-			Buckets:     o.latencyBuckets,
-		}, []string{
-			semconv.MessagingOperationName,
-			semconv.ServerAddress,
-			semconv.ServerPort,
-			semconv.MessagingKafkaConsumerGroup,
-			semconv.MessagingKafkaTopic,
-			semconv.MessagingKafkaPartition,
-			semconv.MessagingKafkaMessageStatus,
-			semconv.ErrorType,
+			Buckets:     o.durationBuckets(),
 		}),
-		inflight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		inflight: o.meter.UpDownCounter(InstrumentOpts{
 			Name:        semconv.MessagingInflightMessages,
 			Help:        "Messages currently being processed.",
+			Unit:        UnitMessages,
+			Labels:      inflightLabels,
 			ConstLabels: constLabels,
-		}, []string{
-			semconv.MessagingOperationName,
-			semconv.ServerAddress,
-			semconv.ServerPort,
-			semconv.MessagingKafkaConsumerGroup,
-			semconv.MessagingKafkaTopic,
-			semconv.MessagingKafkaPartition,
 		}),
-		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+		published: o.meter.Counter(InstrumentOpts{
 			Name:        semconv.MessagingClientPublishedMessages,
 			Help:        "Messages published.",
+			Unit:        UnitMessages,
+			Labels:      opLabels,
 			ConstLabels: constLabels,
-		}, []string{
-			semconv.MessagingOperationName,
-			semconv.ServerAddress,
-			semconv.ServerPort,
-			semconv.MessagingKafkaConsumerGroup,
-			semconv.MessagingKafkaPartition,
-			semconv.MessagingKafkaTopic,
-			semconv.MessagingKafkaMessageStatus,
-			semconv.ErrorType,
 		}),
-		consumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+		consumed: o.meter.Counter(InstrumentOpts{
 			Name:        semconv.MessagingClientConsumedMessages,
 			Help:        "Messages consumed.",
+			Unit:        UnitMessages,
+			Labels:      opLabels,
 			ConstLabels: constLabels,
-		}, []string{
-			semconv.MessagingOperationName,
-			semconv.ServerAddress,
-			semconv.ServerPort,
-			semconv.MessagingKafkaConsumerGroup,
-			semconv.MessagingKafkaTopic,
-			semconv.MessagingKafkaPartition,
-			semconv.MessagingKafkaMessageStatus,
-			semconv.ErrorType,
 		}),
+		batch:    newBatchMetrics(o, constLabels),
+		delivery: newDeliveryMetrics(o, constLabels),
 	}
 }
 
 // Register registers the metrics with the provided registry.
+// With WithMeter, only the instruments that implement prometheus.Collector
+// are registered.
 func (c *Collector) Register(registry prometheus.Registerer) error {
-	if err := registry.Register(c.duration); err != nil {
-		return err
-	}
-
-	if err := registry.Register(c.inflight); err != nil {
-		return err
-	}
-
-	if err := registry.Register(c.published); err != nil {
-		return err
-	}
+	instruments := []any{c.duration, c.inflight, c.published, c.consumed}
+	instruments = append(instruments, c.batch.instruments()...)
+	instruments = append(instruments, c.delivery.instruments()...)
 
-	if err := registry.Register(c.consumed); err != nil {
-		return err
+	for _, pc := range promCollectors(instruments...) {
+		if err := registry.Register(pc); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -138,6 +147,7 @@ func (c *Collector) Register(registry prometheus.Registerer) error {
 func (c *Collector) ConsumerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 	mwopts := &options{
 		errFn:   c.opts.errFn,
+		traceFn: c.opts.traceFn,
 		address: c.opts.address,
 		port:    c.opts.port,
 	}
@@ -149,9 +159,11 @@ func (c *Collector) ConsumerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 	return func(next xkafka.Handler) xkafka.Handler {
 		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
 			start := time.Now()
-			labels := prometheus.Labels{
+			mctx := contextWithTraceID(ctx, mwopts.traceID(ctx))
+			labels := Labels{
 				semconv.MessagingOperationName:      semconv.OperationConsume,
 				semconv.ServerAddress:               mwopts.address,
+				semconv.ServerPort:                  "",
 				semconv.MessagingKafkaConsumerGroup: msg.Group,
 				semconv.MessagingKafkaTopic:         msg.Topic,
 				semconv.MessagingKafkaPartition:     fmt.Sprintf("%d", msg.Partition),
@@ -161,10 +173,10 @@ func (c *Collector) ConsumerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 				labels[semconv.ServerPort] = fmt.Sprintf("%d", mwopts.port)
 			}
 
-			inflight := c.inflight.With(labels)
+			inflightLabels := pick(labels, c.inflightLabels)
 
-			inflight.Inc()
-			defer inflight.Dec()
+			c.inflight.Add(mctx, 1, inflightLabels)
+			defer c.inflight.Add(mctx, -1, inflightLabels)
 
 			msg.AddCallback(func(ackMsg *xkafka.Message) {
 				labels := labels
@@ -173,11 +185,13 @@ func (c *Collector) ConsumerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 				labels[semconv.ErrorType] = ""
 
 				if ackMsg.Err() != nil && mwopts.errFn != nil {
-					labels[semconv.ErrorType] = mwopts.errFn(ackMsg.Err())
+					labels[semconv.ErrorType] = c.errorTypes.limit(mwopts.errFn(ackMsg.Err()))
 				}
 
-				c.duration.With(labels).Observe(time.Since(start).Seconds())
-				c.consumed.With(labels).Inc()
+				opLabels := pick(labels, c.opLabels)
+
+				c.duration.Record(mctx, time.Since(start).Seconds(), opLabels)
+				c.consumed.Add(mctx, 1, opLabels)
 			})
 
 			return next.Handle(ctx, msg)
@@ -187,9 +201,13 @@ func (c *Collector) ConsumerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 
 // BatchConsumerMiddleware returns a middleware that instruments xkafka.BatchConsumer.
 // Options passed to this function will override the Collector options.
+//
+// Besides the message metrics, it records the size, bytes and processing
+// duration of every batch, and counts the batches by xkafka.FlushReason.
 func (c *Collector) BatchConsumerMiddleware(opts ...Option) xkafka.BatchMiddlewareFunc {
 	mwopts := &options{
 		errFn:   c.opts.errFn,
+		traceFn: c.opts.traceFn,
 		address: c.opts.address,
 		port:    c.opts.port,
 	}
@@ -201,11 +219,13 @@ func (c *Collector) BatchConsumerMiddleware(opts ...Option) xkafka.BatchMiddlewa
 	return func(next xkafka.BatchHandler) xkafka.BatchHandler {
 		return xkafka.BatchHandlerFunc(func(ctx context.Context, batch *xkafka.Batch) error {
 			start := time.Now()
+			mctx := contextWithTraceID(ctx, mwopts.traceID(ctx))
 
 			for _, msg := range batch.Messages {
-				labels := prometheus.Labels{
+				labels := Labels{
 					semconv.MessagingOperationName:      semconv.OperationConsume,
 					semconv.ServerAddress:               mwopts.address,
+					semconv.ServerPort:                  "",
 					semconv.MessagingKafkaConsumerGroup: msg.Group,
 					semconv.MessagingKafkaTopic:         msg.Topic,
 					semconv.MessagingKafkaPartition:     fmt.Sprintf("%d", msg.Partition),
@@ -215,18 +235,19 @@ func (c *Collector) BatchConsumerMiddleware(opts ...Option) xkafka.BatchMiddlewa
 					labels[semconv.ServerPort] = fmt.Sprintf("%d", mwopts.port)
 				}
 
-				inflight := c.inflight.With(labels)
+				inflightLabels := pick(labels, c.inflightLabels)
 
-				inflight.Inc()
-				defer inflight.Dec()
+				c.inflight.Add(mctx, 1, inflightLabels)
+				defer c.inflight.Add(mctx, -1, inflightLabels)
 			}
 
 			err := next.HandleBatch(ctx, batch)
 
 			for _, msg := range batch.Messages {
-				labels := prometheus.Labels{
+				labels := Labels{
 					semconv.MessagingOperationName:      semconv.OperationConsume,
 					semconv.ServerAddress:               mwopts.address,
+					semconv.ServerPort:                  "",
 					semconv.MessagingKafkaConsumerGroup: msg.Group,
 					semconv.MessagingKafkaTopic:         msg.Topic,
 					semconv.MessagingKafkaPartition:     fmt.Sprintf("%d", msg.Partition),
@@ -240,13 +261,17 @@ func (c *Collector) BatchConsumerMiddleware(opts ...Option) xkafka.BatchMiddlewa
 				labels[semconv.ErrorType] = ""
 
 				if batch.Err() != nil && mwopts.errFn != nil {
-					labels[semconv.ErrorType] = mwopts.errFn(batch.Err())
+					labels[semconv.ErrorType] = c.errorTypes.limit(mwopts.errFn(batch.Err()))
 				}
 
-				c.duration.With(labels).Observe(time.Since(start).Seconds())
-				c.consumed.With(labels).Inc()
+				opLabels := pick(labels, c.opLabels)
+
+				c.duration.Record(mctx, time.Since(start).Seconds(), opLabels)
+				c.consumed.Add(mctx, 1, opLabels)
 			}
 
+			c.recordBatch(mctx, mwopts, batch, time.Since(start).Seconds())
+
 			return err
 		})
 	}
@@ -257,6 +282,7 @@ func (c *Collector) BatchConsumerMiddleware(opts ...Option) xkafka.BatchMiddlewa
 func (c *Collector) ProducerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 	mwopts := &options{
 		errFn:   c.opts.errFn,
+		traceFn: c.opts.traceFn,
 		address: c.opts.address,
 		port:    c.opts.port,
 	}
@@ -268,9 +294,11 @@ func (c *Collector) ProducerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 	return func(next xkafka.Handler) xkafka.Handler {
 		return xkafka.HandlerFunc(func(ctx context.Context, msg *xkafka.Message) error {
 			start := time.Now()
-			labels := prometheus.Labels{
+			mctx := contextWithTraceID(ctx, mwopts.traceID(ctx))
+			labels := Labels{
 				semconv.MessagingOperationName:      semconv.OperationPublish,
 				semconv.ServerAddress:               mwopts.address,
+				semconv.ServerPort:                  "",
 				semconv.MessagingKafkaTopic:         msg.Topic,
 				semconv.MessagingKafkaPartition:     "",
 				semconv.MessagingKafkaConsumerGroup: msg.Group,
@@ -280,10 +308,10 @@ func (c *Collector) ProducerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 				labels[semconv.ServerPort] = fmt.Sprintf("%d", mwopts.port)
 			}
 
-			inflight := c.inflight.With(labels)
+			inflightLabels := pick(labels, c.inflightLabels)
 
-			inflight.Inc()
-			defer inflight.Dec()
+			c.inflight.Add(mctx, 1, inflightLabels)
+			defer c.inflight.Add(mctx, -1, inflightLabels)
 
 			msg.AddCallback(func(ackMsg *xkafka.Message) {
 				labels := labels
@@ -296,14 +324,76 @@ func (c *Collector) ProducerMiddleware(opts ...Option) xkafka.MiddlewareFunc {
 				labels[semconv.ErrorType] = ""
 
 				if ackMsg.Err() != nil && mwopts.errFn != nil {
-					labels[semconv.ErrorType] = mwopts.errFn(ackMsg.Err())
+					labels[semconv.ErrorType] = c.errorTypes.limit(mwopts.errFn(ackMsg.Err()))
 				}
 
-				c.duration.With(labels).Observe(time.Since(start).Seconds())
-				c.published.With(labels).Inc()
+				opLabels := pick(labels, c.opLabels)
+
+				c.duration.Record(mctx, time.Since(start).Seconds(), opLabels)
+				c.published.Add(mctx, 1, opLabels)
 			})
 
 			return next.Handle(ctx, msg)
 		})
 	}
 }
+
+func (c *Collector) recordBatch(ctx context.Context, o *options, batch *xkafka.Batch, seconds float64) {
+	if len(batch.Messages) == 0 {
+		return
+	}
+
+	labels := Labels{
+		semconv.ServerAddress:               o.address,
+		semconv.ServerPort:                  "",
+		semconv.MessagingKafkaConsumerGroup: batch.Messages[0].Group,
+		semconv.MessagingKafkaMessageStatus: batch.Status.String(),
+		semconv.ErrorType:                   "",
+	}
+
+	if o.port != 0 {
+		labels[semconv.ServerPort] = fmt.Sprintf("%d", o.port)
+	}
+
+	if batch.Err() != nil && o.errFn != nil {
+		labels[semconv.ErrorType] = c.errorTypes.limit(o.errFn(batch.Err()))
+	}
+
+	c.batch.record(ctx, labels, batch, seconds)
+}
+
+// durationBuckets returns the buckets of the duration histograms. Without
+// LatencyBuckets, native histograms have no classic buckets.
+func (o *options) durationBuckets() []float64 {
+	if o.native == nil && len(o.latencyBuckets) == 0 {
+		return defaultLatencyBuckets
+	}
+
+	return o.latencyBuckets
+}
+
+func (o *options) traceID(ctx context.Context) string {
+	if o.traceFn == nil {
+		return ""
+	}
+
+	return o.traceFn(ctx)
+}
+
+func (n *NativeHistograms) setHistogramOpts(opts *prometheus.HistogramOpts) {
+	opts.NativeHistogramBucketFactor = n.BucketFactor
+	opts.NativeHistogramMaxBucketNumber = n.MaxBucketNumber
+	opts.NativeHistogramMinResetDuration = n.MinResetDuration
+
+	if opts.NativeHistogramBucketFactor <= 1 {
+		opts.NativeHistogramBucketFactor = defaultNativeBucketFactor
+	}
+
+	if opts.NativeHistogramMaxBucketNumber == 0 {
+		opts.NativeHistogramMaxBucketNumber = defaultNativeMaxBucketNumber
+	}
+
+	if opts.NativeHistogramMinResetDuration == 0 {
+		opts.NativeHistogramMinResetDuration = defaultNativeMinResetDuration
+	}
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gojekfarm/xtools/xkafka"
 )
@@ -179,3 +181,92 @@ func TestProducerMiddleware(t *testing.T) {
 	err = testutil.GatherAndCompare(reg, strings.NewReader(expected), expectedMetrics...)
 	assert.NoError(t, err)
 }
+
+type traceIDKey struct{}
+
+func TestCollectorNativeHistograms(t *testing.T) {
+	msg := &xkafka.Message{Topic: "test-topic", Group: "test-group", Partition: 1}
+
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		m.AckSuccess()
+
+		return nil
+	})
+
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(NativeHistograms{BucketFactor: 1.05})
+
+	require.NoError(t, collector.Register(reg))
+
+	err := collector.ConsumerMiddleware().Middleware(handler).Handle(context.Background(), msg)
+	require.NoError(t, err)
+
+	h := gatherHistogram(t, reg)
+
+	assert.Empty(t, h.GetBucket())
+	assert.EqualValues(t, 1, h.GetSampleCount())
+	assert.EqualValues(t, 4, h.GetSchema())
+}
+
+func TestCollectorExemplars(t *testing.T) {
+	msg := &xkafka.Message{Topic: "test-topic", Group: "test-group", Partition: 1}
+
+	handler := xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+		m.AckSuccess()
+
+		return nil
+	})
+
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(
+		LatencyBuckets{10},
+		TraceIDExtractor(func(ctx context.Context) string {
+			id, _ := ctx.Value(traceIDKey{}).(string)
+
+			return id
+		}),
+	)
+
+	require.NoError(t, collector.Register(reg))
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	err := collector.ConsumerMiddleware().Middleware(handler).Handle(ctx, msg)
+	require.NoError(t, err)
+
+	h := gatherHistogram(t, reg)
+	require.Len(t, h.GetBucket(), 1)
+
+	exemplar := h.GetBucket()[0].GetExemplar()
+	require.NotNil(t, exemplar)
+	assert.Equal(t, "trace_id", exemplar.GetLabel()[0].GetName())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exemplar.GetLabel()[0].GetValue())
+
+	// messages without a trace ID are observed without exemplars
+	msg = &xkafka.Message{Topic: "test-topic", Group: "test-group", Partition: 1}
+
+	err = collector.ConsumerMiddleware().Middleware(handler).Handle(context.Background(), msg)
+	require.NoError(t, err)
+
+	h = gatherHistogram(t, reg)
+	assert.EqualValues(t, 2, h.GetSampleCount())
+}
+
+func gatherHistogram(t *testing.T, reg *prometheus.Registry) *dto.Histogram {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, mf := range families {
+		if mf.GetName() == "messaging_client_operation_duration" {
+			require.Len(t, mf.GetMetric(), 1)
+
+			return mf.GetMetric()[0].GetHistogram()
+		}
+	}
+
+	t.Fatal("histogram not found")
+
+	return nil
+}
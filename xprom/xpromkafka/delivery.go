@@ -0,0 +1,192 @@
+package xpromkafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+// ProducerQueue is a producer whose queue length is observed by the
+// Collector. It is implemented by xkafka.Producer.
+type ProducerQueue interface {
+	Name() string
+	Len() int
+}
+
+// deliveryMetrics are recorded by the DeliveryReportCallback, once per
+// published message. The queue length is read from the producers added
+// with AddProducer when the metrics are collected.
+type deliveryMetrics struct {
+	durationLabels []string
+	failureLabels  []string
+	queueLabels    []string
+	duration       Histogram
+	failures       Counter
+	queue          Observable
+
+	mu        sync.Mutex
+	producers []producerQueue
+}
+
+type producerQueue struct {
+	producer ProducerQueue
+	labels   Labels
+}
+
+func newDeliveryMetrics(o options, constLabels Labels) *deliveryMetrics {
+	durationLabels := o.filterLabels([]string{
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingClientID,
+		semconv.MessagingKafkaTopic,
+		semconv.MessagingKafkaPartition,
+		semconv.MessagingKafkaMessageStatus,
+	})
+	failureLabels := o.filterLabels([]string{
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingClientID,
+		semconv.MessagingKafkaTopic,
+		semconv.MessagingKafkaErrorCode,
+	})
+	queueLabels := o.filterLabels([]string{
+		semconv.ServerAddress,
+		semconv.ServerPort,
+		semconv.MessagingClientID,
+	})
+
+	m := &deliveryMetrics{
+		durationLabels: durationLabels,
+		failureLabels:  failureLabels,
+		queueLabels:    queueLabels,
+		duration: o.meter.Histogram(InstrumentOpts{
+			Name:        semconv.MessagingKafkaProducerDeliveryDuration,
+			Help:        "Duration from the enqueue of a message to its delivery report.",
+			Unit:        UnitSeconds,
+			Labels:      durationLabels,
+			ConstLabels: constLabels,
+			Buckets:     o.durationBuckets(),
+		}),
+		failures: o.meter.Counter(InstrumentOpts{
+			Name:        semconv.MessagingKafkaProducerDeliveryFailures,
+			Help:        "Messages that failed to be delivered, by Kafka error code.",
+			Unit:        UnitMessages,
+			Labels:      failureLabels,
+			ConstLabels: constLabels,
+		}),
+	}
+
+	m.queue = o.meter.ObservableGauge(InstrumentOpts{
+		Name:        semconv.MessagingKafkaProducerQueueMessages,
+		Help:        "Messages waiting to be sent or acknowledged.",
+		Unit:        UnitMessages,
+		Labels:      queueLabels,
+		ConstLabels: constLabels,
+	}, m.observeQueue)
+
+	return m
+}
+
+func (m *deliveryMetrics) instruments() []any {
+	return []any{m.duration, m.failures, m.queue}
+}
+
+func (m *deliveryMetrics) observeQueue(o Observer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, q := range m.producers {
+		o.Observe(float64(q.producer.Len()), q.labels)
+	}
+}
+
+// AddProducer adds a producer whose queue length is exported, when the
+// metrics are collected, as `messaging_kafka_producer_queue_messages`.
+// Options passed to this function will override the Collector options.
+//
+// The `messaging_client_id` label is set to the producer name.
+func (c *Collector) AddProducer(p ProducerQueue, opts ...Option) {
+	qopts := &options{
+		address: c.opts.address,
+		port:    c.opts.port,
+	}
+
+	for _, opt := range opts {
+		opt.apply(qopts)
+	}
+
+	labels := Labels{
+		semconv.ServerAddress:     qopts.address,
+		semconv.ServerPort:        "",
+		semconv.MessagingClientID: p.Name(),
+	}
+
+	if qopts.port != 0 {
+		labels[semconv.ServerPort] = fmt.Sprintf("%d", qopts.port)
+	}
+
+	c.delivery.mu.Lock()
+	defer c.delivery.mu.Unlock()
+
+	c.delivery.producers = append(c.delivery.producers, producerQueue{
+		producer: p,
+		labels:   pick(labels, c.delivery.queueLabels),
+	})
+}
+
+// DeliveryReportCallback returns an xkafka.DeliveryReportCallback that
+// records the delivery latency of published messages, from enqueue to
+// delivery report, and the delivery failures by Kafka error code.
+// It covers both Publish and AsyncPublish. Use AddProducer to export
+// the producer queue length.
+// Options passed to this function will override the Collector options.
+//
+// The `messaging_client_id` label is set to the producer name.
+func (c *Collector) DeliveryReportCallback(opts ...Option) xkafka.DeliveryReportCallback {
+	cbopts := &options{
+		address: c.opts.address,
+		port:    c.opts.port,
+	}
+
+	for _, opt := range opts {
+		opt.apply(cbopts)
+	}
+
+	return func(r xkafka.DeliveryReport) {
+		ctx := context.Background()
+		labels := Labels{
+			semconv.ServerAddress:               cbopts.address,
+			semconv.ServerPort:                  "",
+			semconv.MessagingClientID:           r.Producer,
+			semconv.MessagingKafkaTopic:         r.Message.Topic,
+			semconv.MessagingKafkaPartition:     "",
+			semconv.MessagingKafkaMessageStatus: r.Message.Status.String(),
+			semconv.MessagingKafkaErrorCode:     "",
+		}
+
+		if cbopts.port != 0 {
+			labels[semconv.ServerPort] = fmt.Sprintf("%d", cbopts.port)
+		}
+
+		if r.Message.Partition != int32(kafka.PartitionAny) {
+			labels[semconv.MessagingKafkaPartition] = strconv.Itoa(int(r.Message.Partition))
+		}
+
+		if r.ErrorCode != kafka.ErrNoError {
+			labels[semconv.MessagingKafkaErrorCode] = r.ErrorCode.String()
+
+			c.delivery.failures.Add(ctx, 1, pick(labels, c.delivery.failureLabels))
+		}
+
+		// messages that could not be enqueued have no delivery latency
+		if r.Latency > 0 {
+			c.delivery.duration.Record(ctx, r.Latency.Seconds(), pick(labels, c.delivery.durationLabels))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package xpromkafka
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+func TestDeliveryReportCallback(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(LatencyBuckets{0.1, 1})
+
+	require.NoError(t, collector.Register(reg))
+
+	cb := collector.DeliveryReportCallback(Address("localhost"), Port(9092))
+
+	delivered := &xkafka.Message{Topic: "test-topic", Partition: 2}
+	delivered.AckSuccess()
+
+	timedOut := &xkafka.Message{Topic: "test-topic", Partition: -1}
+	timedOut.AckFail(errors.New("message timed out"))
+
+	queueFull := &xkafka.Message{Topic: "test-topic", Partition: -1}
+	queueFull.AckFail(errors.New("queue full"))
+
+	cb(xkafka.DeliveryReport{
+		Producer: "test-publisher",
+		Message:  delivered,
+		Latency:  50 * time.Millisecond,
+	})
+	cb(xkafka.DeliveryReport{
+		Producer:  "test-publisher",
+		Message:   timedOut,
+		Latency:   2 * time.Second,
+		ErrorCode: kafka.ErrMsgTimedOut,
+	})
+	cb(xkafka.DeliveryReport{
+		Producer:  "test-publisher",
+		Message:   queueFull,
+		ErrorCode: kafka.ErrQueueFull,
+	})
+
+	expected := `
+	# HELP messaging_kafka_producer_delivery_duration Duration from the enqueue of a message to its delivery report.
+	# TYPE messaging_kafka_producer_delivery_duration histogram
+	messaging_kafka_producer_delivery_duration_bucket{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="",messaging_kafka_message_status="FAIL",messaging_system="kafka",server_address="localhost",server_port="9092",le="0.1"} 0
+	messaging_kafka_producer_delivery_duration_bucket{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="",messaging_kafka_message_status="FAIL",messaging_system="kafka",server_address="localhost",server_port="9092",le="1"} 0
+	messaging_kafka_producer_delivery_duration_bucket{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="",messaging_kafka_message_status="FAIL",messaging_system="kafka",server_address="localhost",server_port="9092",le="+Inf"} 1
+	messaging_kafka_producer_delivery_duration_sum{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="",messaging_kafka_message_status="FAIL",messaging_system="kafka",server_address="localhost",server_port="9092"} 2
+	messaging_kafka_producer_delivery_duration_count{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="",messaging_kafka_message_status="FAIL",messaging_system="kafka",server_address="localhost",server_port="9092"} 1
+	messaging_kafka_producer_delivery_duration_bucket{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="2",messaging_kafka_message_status="SUCCESS",messaging_system="kafka",server_address="localhost",server_port="9092",le="0.1"} 1
+	messaging_kafka_producer_delivery_duration_bucket{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="2",messaging_kafka_message_status="SUCCESS",messaging_system="kafka",server_address="localhost",server_port="9092",le="1"} 1
+	messaging_kafka_producer_delivery_duration_bucket{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="2",messaging_kafka_message_status="SUCCESS",messaging_system="kafka",server_address="localhost",server_port="9092",le="+Inf"} 1
+	messaging_kafka_producer_delivery_duration_sum{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="2",messaging_kafka_message_status="SUCCESS",messaging_system="kafka",server_address="localhost",server_port="9092"} 0.05
+	messaging_kafka_producer_delivery_duration_count{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_destination_partition_id="2",messaging_kafka_message_status="SUCCESS",messaging_system="kafka",server_address="localhost",server_port="9092"} 1
+	# HELP messaging_kafka_producer_delivery_failures Messages that failed to be delivered, by Kafka error code.
+	# TYPE messaging_kafka_producer_delivery_failures counter
+	messaging_kafka_producer_delivery_failures{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_kafka_error_code="Local: Message timed out",messaging_system="kafka",server_address="localhost",server_port="9092"} 1
+	messaging_kafka_producer_delivery_failures{messaging_client_id="test-publisher",messaging_destination_name="test-topic",messaging_kafka_error_code="Local: Queue full",messaging_system="kafka",server_address="localhost",server_port="9092"} 1
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"messaging_kafka_producer_delivery_duration",
+		"messaging_kafka_producer_delivery_failures",
+	)
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(reg, "messaging_kafka_producer_queue_messages")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+type fakeProducer struct {
+	name string
+	len  int
+}
+
+func (p *fakeProducer) Name() string { return p.name }
+func (p *fakeProducer) Len() int     { return p.len }
+
+func TestCollectorAddProducer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewCollector(Address("localhost"), Port(9092))
+
+	require.NoError(t, collector.Register(reg))
+
+	orders := &fakeProducer{name: "orders", len: 10}
+	payments := &fakeProducer{name: "payments", len: 3}
+
+	collector.AddProducer(orders)
+	collector.AddProducer(payments, Address("payments-broker"), Port(9093))
+
+	expected := `
+	# HELP messaging_kafka_producer_queue_messages Messages waiting to be sent or acknowledged.
+	# TYPE messaging_kafka_producer_queue_messages gauge
+	messaging_kafka_producer_queue_messages{messaging_client_id="orders",messaging_system="kafka",server_address="localhost",server_port="9092"} 10
+	messaging_kafka_producer_queue_messages{messaging_client_id="payments",messaging_system="kafka",server_address="payments-broker",server_port="9093"} 3
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "messaging_kafka_producer_queue_messages")
+	assert.NoError(t, err)
+
+	// the queue length is read when the metrics are collected
+	orders.len = 0
+
+	expected = `
+	# HELP messaging_kafka_producer_queue_messages Messages waiting to be sent or acknowledged.
+	# TYPE messaging_kafka_producer_queue_messages gauge
+	messaging_kafka_producer_queue_messages{messaging_client_id="orders",messaging_system="kafka",server_address="localhost",server_port="9092"} 0
+	messaging_kafka_producer_queue_messages{messaging_client_id="payments",messaging_system="kafka",server_address="payments-broker",server_port="9093"} 3
+	`
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(expected), "messaging_kafka_producer_queue_messages")
+	assert.NoError(t, err)
+}
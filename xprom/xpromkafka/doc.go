@@ -1,3 +1,13 @@
 // Package xpromkafka provides a Prometheus metrics middleware for
 // xkafka.Producer and xkafka.Consumer.
+//
+// The Collector, LagCollector and StatsCollector record with a Prometheus
+// meter by default. WithMeter records the same metrics with another backend,
+// such as OpenTelemetry with the otelmeter package.
+//
+// StatsCollector exports the librdkafka statistics, like broker round-trip
+// times, queue sizes and consumer lag, reported with xkafka.StatsCallback.
+//
+// LagCollector periodically queries the committed offsets and watermarks of
+// the partitions assigned to consumers, and exports the consumer group lag.
 package xpromkafka
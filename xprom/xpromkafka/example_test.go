@@ -2,6 +2,7 @@ package xpromkafka_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -130,3 +131,97 @@ func ExampleCollector_ProducerMiddleware_multipleProducers() {
 
 	// Produce messages.
 }
+
+func ExampleCollector_DeliveryReportCallback() {
+	reg := prometheus.NewRegistry()
+	collector := xpromkafka.NewCollector(
+		xpromkafka.LatencyBuckets{0.01, 0.05, 0.1, 0.5, 1, 5},
+		xpromkafka.Address("localhost"),
+		xpromkafka.Port(9092),
+	)
+
+	_ = collector.Register(reg)
+
+	producer, _ := xkafka.NewProducer(
+		"test-publisher",
+		xkafka.Brokers{"localhost:9092"},
+		collector.DeliveryReportCallback(),
+	)
+
+	producer.Use(collector.ProducerMiddleware())
+	collector.AddProducer(producer)
+
+	// Start the producer, and publish messages with AsyncPublish.
+}
+
+func ExampleStatsCollector() {
+	reg := prometheus.NewRegistry()
+	stats := xpromkafka.NewStatsCollector()
+
+	_ = stats.Register(reg)
+
+	consumer, _ := xkafka.NewConsumer(
+		"test-group",
+		handler,
+		xkafka.Brokers{"localhost:9092"},
+		xkafka.Topics{"test-topic"},
+		xkafka.ConfigMap{"statistics.interval.ms": 15000},
+		stats.StatsCallback(),
+	)
+
+	producer, _ := xkafka.NewProducer(
+		"test-publisher",
+		xkafka.Brokers{"localhost:9092"},
+		xkafka.ConfigMap{"statistics.interval.ms": 15000},
+		stats.StatsCallback(),
+	)
+
+	// Start consuming and producing messages.
+	_, _ = consumer, producer
+}
+
+func ExampleLagCollector() {
+	reg := prometheus.NewRegistry()
+	lag := xpromkafka.NewLagCollector(
+		xpromkafka.LagInterval(time.Minute),
+		xpromkafka.LagErrorHandler(func(name string, err error) {
+			// Log errors.
+		}),
+	)
+
+	_ = lag.Register(reg)
+
+	consumer, _ := xkafka.NewConsumer(
+		"test-group",
+		handler,
+		xkafka.Brokers{"localhost:9092"},
+		xkafka.Topics{"test-topic"},
+	)
+
+	lag.Add(consumer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = lag.Run(ctx) }()
+
+	// Start consuming messages.
+}
+
+func ExampleTraceIDExtractor() {
+	reg := prometheus.NewRegistry()
+	collector := xpromkafka.NewCollector(
+		xpromkafka.NativeHistograms{BucketFactor: 1.1},
+		xpromkafka.TraceIDExtractor(func(ctx context.Context) string {
+			// with OpenTelemetry:
+			// sc := trace.SpanContextFromContext(ctx)
+			// if sc.IsSampled() { return sc.TraceID().String() }
+			return ""
+		}),
+	)
+
+	_ = collector.Register(reg)
+
+	// Exemplars are exposed with the OpenMetrics format:
+	// promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
@@ -0,0 +1,213 @@
+package xpromkafka
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+const defaultLagInterval = 30 * time.Second
+
+// LagSource reports the lag of a consumer group.
+// It is implemented by xkafka.Consumer and xkafka.BatchConsumer.
+type LagSource interface {
+	Name() string
+	Lag(ctx context.Context) ([]xkafka.PartitionLag, error)
+}
+
+// LagCollector periodically queries the lag of consumers and exports the
+// committed offsets, high watermarks and lag of the assigned partitions,
+// and the number of assigned partitions.
+//
+// Unlike the consumer lag reported by StatsCollector, the lag is queried
+// from the brokers, and does not require `statistics.interval.ms`.
+type LagCollector struct {
+	interval time.Duration
+	errFn    func(name string, err error)
+
+	mu      sync.Mutex
+	sources []LagSource
+	lags    map[string][]xkafka.PartitionLag
+
+	collectors []prometheus.Collector
+}
+
+// NewLagCollector creates a new LagCollector.
+// Default values:
+// - LagInterval: 30s
+func NewLagCollector(opts ...Option) *LagCollector {
+	o := options{
+		lagInterval: defaultLagInterval,
+		lagErrFn:    func(string, error) {},
+	}
+
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	if o.meter == nil {
+		o.meter = &promMeter{}
+	}
+
+	c := &LagCollector{
+		interval: o.lagInterval,
+		errFn:    o.lagErrFn,
+		lags:     make(map[string][]xkafka.PartitionLag),
+	}
+
+	constLabels := Labels{
+		semconv.MessagingSystem: semconv.SystemKafka,
+	}
+
+	partitionLabels := []string{
+		semconv.MessagingKafkaConsumerGroup,
+		semconv.MessagingKafkaTopic,
+		semconv.MessagingKafkaPartition,
+	}
+
+	c.collectors = promCollectors(
+		o.meter.ObservableGauge(InstrumentOpts{
+			Name:        semconv.MessagingKafkaConsumerLag,
+			Help:        "Messages in the partition after the committed offset of the consumer group.",
+			Unit:        UnitMessages,
+			Labels:      partitionLabels,
+			ConstLabels: constLabels,
+		}, c.observePartitions(func(l xkafka.PartitionLag) (float64, bool) {
+			return float64(l.Lag), true
+		})),
+		o.meter.ObservableGauge(InstrumentOpts{
+			Name:        semconv.MessagingKafkaConsumerCommittedOffset,
+			Help:        "Committed offset of the consumer group.",
+			Labels:      partitionLabels,
+			ConstLabels: constLabels,
+		}, c.observePartitions(func(l xkafka.PartitionLag) (float64, bool) {
+			// no offset is committed yet
+			return float64(l.Committed), l.Committed >= 0
+		})),
+		o.meter.ObservableGauge(InstrumentOpts{
+			Name:        semconv.MessagingKafkaPartitionHighWatermark,
+			Help:        "Offset of the next message in the partition.",
+			Labels:      partitionLabels,
+			ConstLabels: constLabels,
+		}, c.observePartitions(func(l xkafka.PartitionLag) (float64, bool) {
+			return float64(l.HighWatermark), true
+		})),
+		o.meter.ObservableGauge(InstrumentOpts{
+			Name:        semconv.MessagingKafkaConsumerAssignedPartitions,
+			Help:        "Partitions assigned to the consumer.",
+			Labels:      []string{semconv.MessagingKafkaConsumerGroup},
+			ConstLabels: constLabels,
+		}, c.observeAssigned),
+	)
+
+	return c
+}
+
+// Register registers the collector with the provided registry.
+func (c *LagCollector) Register(registry prometheus.Registerer) error {
+	return registry.Register(c)
+}
+
+// Add adds consumers to the collector. Consumers can be added
+// before or after Run is called. Consumers are identified by name, so
+// consumers of the same group in a process should share a single source.
+func (c *LagCollector) Add(sources ...LagSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sources = append(c.sources, sources...)
+}
+
+// Run queries the lag of the consumers every LagInterval,
+// until the context is cancelled.
+func (c *LagCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *LagCollector) refresh(ctx context.Context) {
+	c.mu.Lock()
+	sources := append([]LagSource(nil), c.sources...)
+	c.mu.Unlock()
+
+	for _, src := range sources {
+		if ctx.Err() != nil {
+			return
+		}
+
+		lags, err := src.Lag(ctx)
+		if err != nil {
+			c.errFn(src.Name(), err)
+
+			continue
+		}
+
+		c.mu.Lock()
+		c.lags[src.Name()] = lags
+		c.mu.Unlock()
+	}
+}
+
+// Describe implements prometheus.Collector. Instruments of a Meter set
+// with WithMeter are not described, unless they are Prometheus collectors.
+func (c *LagCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, pc := range c.collectors {
+		pc.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *LagCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, pc := range c.collectors {
+		pc.Collect(ch)
+	}
+}
+
+// observePartitions returns an ObserveFunc that reports a value of every
+// assigned partition, if value returns true.
+func (c *LagCollector) observePartitions(value func(l xkafka.PartitionLag) (float64, bool)) ObserveFunc {
+	return func(o Observer) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for group, lags := range c.lags {
+			for _, l := range lags {
+				v, ok := value(l)
+				if !ok {
+					continue
+				}
+
+				o.Observe(v, Labels{
+					semconv.MessagingKafkaConsumerGroup: group,
+					semconv.MessagingKafkaTopic:         l.Topic,
+					semconv.MessagingKafkaPartition:     strconv.Itoa(int(l.Partition)),
+				})
+			}
+		}
+	}
+}
+
+func (c *LagCollector) observeAssigned(o Observer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for group, lags := range c.lags {
+		o.Observe(float64(len(lags)), Labels{semconv.MessagingKafkaConsumerGroup: group})
+	}
+}
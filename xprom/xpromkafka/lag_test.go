@@ -0,0 +1,133 @@
+package xpromkafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/xtools/xkafka"
+)
+
+type fakeLagSource struct {
+	name string
+
+	mu   sync.Mutex
+	lags []xkafka.PartitionLag
+	err  error
+}
+
+func (f *fakeLagSource) Name() string { return f.name }
+
+func (f *fakeLagSource) Lag(context.Context) ([]xkafka.PartitionLag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.lags, f.err
+}
+
+func (f *fakeLagSource) set(lags []xkafka.PartitionLag, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lags, f.err = lags, err
+}
+
+func TestLagCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewLagCollector()
+
+	require.NoError(t, collector.Register(reg))
+
+	collector.Add(&fakeLagSource{
+		name: "test-group",
+		lags: []xkafka.PartitionLag{
+			{Topic: "test-topic", Partition: 0, Committed: 90, HighWatermark: 100, Lag: 10},
+			{Topic: "test-topic", Partition: 1, Committed: -1, HighWatermark: 20, Lag: 5},
+		},
+	})
+
+	collector.refresh(context.Background())
+
+	expected := `
+	# HELP messaging_kafka_consumer_assigned_partitions Partitions assigned to the consumer.
+	# TYPE messaging_kafka_consumer_assigned_partitions gauge
+	messaging_kafka_consumer_assigned_partitions{messaging_consumer_group_name="test-group",messaging_system="kafka"} 2
+	# HELP messaging_kafka_consumer_committed_offset Committed offset of the consumer group.
+	# TYPE messaging_kafka_consumer_committed_offset gauge
+	messaging_kafka_consumer_committed_offset{messaging_consumer_group_name="test-group",messaging_destination_name="test-topic",messaging_destination_partition_id="0",messaging_system="kafka"} 90
+	# HELP messaging_kafka_consumer_lag Messages in the partition after the committed offset of the consumer group.
+	# TYPE messaging_kafka_consumer_lag gauge
+	messaging_kafka_consumer_lag{messaging_consumer_group_name="test-group",messaging_destination_name="test-topic",messaging_destination_partition_id="0",messaging_system="kafka"} 10
+	messaging_kafka_consumer_lag{messaging_consumer_group_name="test-group",messaging_destination_name="test-topic",messaging_destination_partition_id="1",messaging_system="kafka"} 5
+	# HELP messaging_kafka_partition_high_watermark Offset of the next message in the partition.
+	# TYPE messaging_kafka_partition_high_watermark gauge
+	messaging_kafka_partition_high_watermark{messaging_consumer_group_name="test-group",messaging_destination_name="test-topic",messaging_destination_partition_id="0",messaging_system="kafka"} 100
+	messaging_kafka_partition_high_watermark{messaging_consumer_group_name="test-group",messaging_destination_name="test-topic",messaging_destination_partition_id="1",messaging_system="kafka"} 20
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
+func TestLagCollectorRun(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		reported []string
+	)
+
+	src := &fakeLagSource{name: "test-group"}
+	src.set(nil, assert.AnError)
+
+	collector := NewLagCollector(
+		LagInterval(time.Millisecond),
+		LagErrorHandler(func(name string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			reported = append(reported, name+": "+err.Error())
+		}),
+	)
+	collector.Add(src)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- collector.Run(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(reported) > 0
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, "test-group: "+assert.AnError.Error(), reported[0])
+	mu.Unlock()
+
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	src.set([]xkafka.PartitionLag{
+		{Topic: "test-topic", Partition: 0, Committed: 1, HighWatermark: 2, Lag: 1},
+	}, nil)
+
+	assert.Eventually(t, func() bool {
+		return testutil.CollectAndCount(collector) == 4
+	}, time.Second, time.Millisecond)
+
+	// the last lag is kept on errors
+	src.set(nil, errors.New("lag unavailable"))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, 4, testutil.CollectAndCount(collector))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
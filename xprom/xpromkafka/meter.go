@@ -0,0 +1,231 @@
+package xpromkafka
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Units of the Collector instruments, following the UCUM case-sensitive
+// codes used by the OpenTelemetry semantic conventions.
+const (
+	UnitSeconds  = "s"
+	UnitBytes    = "By"
+	UnitMessages = "{message}"
+	UnitBatches  = "{batch}"
+)
+
+// Labels are the label values of a measurement, by label name.
+type Labels = map[string]string
+
+// Meter creates the instruments recorded by the Collector, LagCollector
+// and StatsCollector. They use a Prometheus meter by default, and WithMeter
+// replaces it with another backend, such as the OpenTelemetry meter of the
+// otelmeter package.
+//
+// Instruments are created once per collector, with the metric names and
+// labels of xprom/semconv.
+type Meter interface {
+	Histogram(opts InstrumentOpts) Histogram
+	Counter(opts InstrumentOpts) Counter
+	UpDownCounter(opts InstrumentOpts) Counter
+	ObservableGauge(opts InstrumentOpts, fn ObserveFunc) Observable
+	ObservableCounter(opts InstrumentOpts, fn ObserveFunc) Observable
+}
+
+// InstrumentOpts describes an instrument.
+type InstrumentOpts struct {
+	Name string
+	Help string
+	Unit string
+	// Labels are the names of the labels set on every measurement.
+	Labels []string
+	// ConstLabels are set on every measurement of the instrument.
+	ConstLabels Labels
+	// Buckets are the histogram bucket boundaries. Nil uses the meter defaults.
+	Buckets []float64
+}
+
+// Histogram records the distribution of measurements.
+type Histogram interface {
+	Record(ctx context.Context, value float64, labels Labels)
+}
+
+// Counter adds measurements to a sum. Counters created with Meter.Counter
+// only accept non-negative values.
+type Counter interface {
+	Add(ctx context.Context, value float64, labels Labels)
+}
+
+// Observer reports the values of an observable instrument.
+type Observer interface {
+	Observe(value float64, labels Labels)
+}
+
+// ObserveFunc reports the current values of an observable instrument.
+// It is called every time the instrument is collected.
+type ObserveFunc func(o Observer)
+
+// Observable is an instrument whose values are reported by an ObserveFunc
+// when it is collected, instead of being recorded as they change.
+type Observable any
+
+// WithMeter sets the Meter used to create the instruments of the Collector,
+// LagCollector and StatsCollector. Their Register methods only register
+// instruments that implement prometheus.Collector.
+func WithMeter(m Meter) Option {
+	return optionFunc(func(o *options) {
+		o.meter = m
+	})
+}
+
+// promMeter creates Prometheus metric vectors. The duration histograms
+// are native histograms when NativeHistograms is set.
+type promMeter struct {
+	native *NativeHistograms
+}
+
+func (m *promMeter) Histogram(opts InstrumentOpts) Histogram {
+	hopts := prometheus.HistogramOpts{
+		Name:        opts.Name,
+		Help:        opts.Help,
+		ConstLabels: opts.ConstLabels,
+		Buckets:     opts.Buckets,
+	}
+
+	if m.native != nil && opts.Unit == UnitSeconds {
+		m.native.setHistogramOpts(&hopts)
+	}
+
+	return promHistogram{prometheus.NewHistogramVec(hopts, opts.Labels)}
+}
+
+func (m *promMeter) Counter(opts InstrumentOpts) Counter {
+	return promCounter{prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        opts.Name,
+		Help:        opts.Help,
+		ConstLabels: opts.ConstLabels,
+	}, opts.Labels)}
+}
+
+func (m *promMeter) UpDownCounter(opts InstrumentOpts) Counter {
+	return promGauge{prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        opts.Name,
+		Help:        opts.Help,
+		ConstLabels: opts.ConstLabels,
+	}, opts.Labels)}
+}
+
+func (m *promMeter) ObservableGauge(opts InstrumentOpts, fn ObserveFunc) Observable {
+	return newPromObservable(opts, prometheus.GaugeValue, fn)
+}
+
+func (m *promMeter) ObservableCounter(opts InstrumentOpts, fn ObserveFunc) Observable {
+	return newPromObservable(opts, prometheus.CounterValue, fn)
+}
+
+type promHistogram struct{ *prometheus.HistogramVec }
+
+func (h promHistogram) Record(ctx context.Context, value float64, labels Labels) {
+	observe(h.With(labels), value, traceIDFromContext(ctx))
+}
+
+type promCounter struct{ *prometheus.CounterVec }
+
+func (c promCounter) Add(ctx context.Context, value float64, labels Labels) {
+	add(c.With(labels), value, traceIDFromContext(ctx))
+}
+
+type promGauge struct{ *prometheus.GaugeVec }
+
+func (g promGauge) Add(_ context.Context, value float64, labels Labels) {
+	g.With(labels).Add(value)
+}
+
+// promObservable is a prometheus.Collector that exports the values
+// reported by its ObserveFunc as const metrics.
+type promObservable struct {
+	desc      *prometheus.Desc
+	labels    []string
+	valueType prometheus.ValueType
+	fn        ObserveFunc
+}
+
+func newPromObservable(opts InstrumentOpts, valueType prometheus.ValueType, fn ObserveFunc) *promObservable {
+	return &promObservable{
+		desc:      prometheus.NewDesc(opts.Name, opts.Help, opts.Labels, opts.ConstLabels),
+		labels:    opts.Labels,
+		valueType: valueType,
+		fn:        fn,
+	}
+}
+
+func (o *promObservable) Describe(ch chan<- *prometheus.Desc) { ch <- o.desc }
+
+func (o *promObservable) Collect(ch chan<- prometheus.Metric) {
+	o.fn(observerFunc(func(value float64, labels Labels) {
+		values := make([]string, len(o.labels))
+		for i, name := range o.labels {
+			values[i] = labels[name]
+		}
+
+		ch <- prometheus.MustNewConstMetric(o.desc, o.valueType, value, values...)
+	}))
+}
+
+type observerFunc func(value float64, labels Labels)
+
+func (f observerFunc) Observe(value float64, labels Labels) { f(value, labels) }
+
+// promCollectors returns the instruments that implement prometheus.Collector.
+func promCollectors(instruments ...any) []prometheus.Collector {
+	var collectors []prometheus.Collector
+
+	for _, i := range instruments {
+		if pc, ok := i.(prometheus.Collector); ok {
+			collectors = append(collectors, pc)
+		}
+	}
+
+	return collectors
+}
+
+type exemplarKey struct{}
+
+// contextWithTraceID returns a context that carries the trace ID extracted
+// with TraceIDExtractor, which the Prometheus meter sets as exemplar.
+func contextWithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, exemplarKey{}, traceID)
+}
+
+func traceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(exemplarKey{}).(string)
+
+	return traceID
+}
+
+// observe records the value with a trace ID exemplar, if any.
+func observe(o prometheus.Observer, v float64, traceID string) {
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && traceID != "" {
+		eo.ObserveWithExemplar(v, prometheus.Labels{exemplarTraceID: traceID})
+
+		return
+	}
+
+	o.Observe(v)
+}
+
+// add increments the counter with a trace ID exemplar, if any.
+func add(c prometheus.Counter, v float64, traceID string) {
+	if ea, ok := c.(prometheus.ExemplarAdder); ok && traceID != "" {
+		ea.AddWithExemplar(v, prometheus.Labels{exemplarTraceID: traceID})
+
+		return
+	}
+
+	c.Add(v)
+}
@@ -1,5 +1,10 @@
 package xpromkafka
 
+import (
+	"context"
+	"time"
+)
+
 // Option configures the collector.
 type Option interface {
 	apply(*options)
@@ -11,11 +16,25 @@ func (f optionFunc) apply(o *options) { f(o) }
 
 type errorClassifier func(error) string
 
+type traceIDExtractor func(context.Context) string
+
 type options struct {
 	latencyBuckets []float64
 	errFn          errorClassifier
 	address        string
 	port           int
+	statsExpiry    time.Duration
+	native         *NativeHistograms
+	traceFn        traceIDExtractor
+	dropLabels     []string
+	allowLabels    []string
+	maxErrorTypes  int
+	lagInterval    time.Duration
+	lagErrFn       func(name string, err error)
+
+	meter             Meter
+	batchSizeBuckets  []float64
+	batchBytesBuckets []float64
 }
 
 // LatencyBuckets configures the latency buckets.
@@ -25,6 +44,27 @@ func (l LatencyBuckets) apply(o *options) {
 	o.latencyBuckets = l
 }
 
+// NativeHistograms configures the duration histogram as a Prometheus native
+// histogram, with sparse buckets that adapt to the observed values.
+// Classic buckets are exposed too only if LatencyBuckets is set.
+//
+// Native histograms require the Prometheus server to scrape with the
+// protobuf format, and `--enable-feature=native-histograms`.
+type NativeHistograms struct {
+	// BucketFactor is the maximum ratio between the bounds of consecutive
+	// buckets. Lower factors are more precise, and use more buckets.
+	// Defaults to 1.1.
+	BucketFactor float64
+	// MaxBucketNumber limits the number of buckets of each series.
+	// The resolution is reduced when it is exceeded. Defaults to 160.
+	MaxBucketNumber uint32
+	// MinResetDuration is the minimum duration between resets of the
+	// histogram when MaxBucketNumber is exceeded. Defaults to 1h.
+	MinResetDuration time.Duration
+}
+
+func (n NativeHistograms) apply(o *options) { o.native = &n }
+
 // Address sets `server_address` label.
 type Address string
 
@@ -41,3 +81,35 @@ func ErrorClassifer(fn func(error) string) Option {
 		o.errFn = fn
 	})
 }
+
+// TraceIDExtractor extracts the trace ID from the handler context. When it
+// returns a non-empty ID, the duration and message count observations carry
+// it as a `trace_id` exemplar, which links the metrics to the trace.
+//
+// Exemplars are exposed only with the OpenMetrics format, for example with
+// promhttp.HandlerOpts.EnableOpenMetrics.
+func TraceIDExtractor(fn func(ctx context.Context) string) Option {
+	return optionFunc(func(o *options) {
+		o.traceFn = fn
+	})
+}
+
+// StatsExpiry sets how long the statistics of a client are exported after
+// they were last reported. Use 0 to export them until they are replaced.
+type StatsExpiry time.Duration
+
+func (s StatsExpiry) apply(o *options) { o.statsExpiry = time.Duration(s) }
+
+// LagInterval sets how often the LagCollector queries the consumer lag.
+type LagInterval time.Duration
+
+func (l LagInterval) apply(o *options) { o.lagInterval = time.Duration(l) }
+
+// LagErrorHandler is called with the consumer name when the LagCollector
+// fails to query the consumer lag. The previous lag is exported until
+// the next successful query.
+func LagErrorHandler(fn func(name string, err error)) Option {
+	return optionFunc(func(o *options) {
+		o.lagErrFn = fn
+	})
+}
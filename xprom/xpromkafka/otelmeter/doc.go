@@ -0,0 +1,9 @@
+// Package otelmeter records the metrics of the xpromkafka collectors with the
+// OpenTelemetry metric API, for services that export metrics with OTLP
+// instead of Prometheus scraping.
+//
+// The metrics keep the xprom/semconv names and labels, so that dashboards
+// work with both backends. Exemplars are added by the OpenTelemetry SDK
+// from the span in the handler context; xpromkafka.TraceIDExtractor
+// is used only by the Prometheus meter.
+package otelmeter
@@ -0,0 +1,29 @@
+package otelmeter_test
+
+import (
+	"go.opentelemetry.io/otel"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/xpromkafka"
+	"github.com/gojekfarm/xtools/xprom/xpromkafka/otelmeter"
+)
+
+func ExampleNew() {
+	// Uses the global MeterProvider, configured with an OTLP exporter.
+	meter := otelmeter.New(otel.Meter("github.com/gojekfarm/xtools/xprom/xpromkafka"))
+
+	collector := xpromkafka.NewCollector(
+		xpromkafka.WithMeter(meter),
+		xpromkafka.Address("localhost"),
+		xpromkafka.Port(9092),
+	)
+
+	producer, _ := xkafka.NewProducer(
+		"test-publisher",
+		xkafka.Brokers{"localhost:9092"},
+	)
+
+	producer.Use(collector.ProducerMiddleware())
+
+	// Produce messages.
+}
@@ -0,0 +1,163 @@
+package otelmeter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/gojekfarm/xtools/xprom/xpromkafka"
+)
+
+// Meter records the instruments of the xpromkafka collectors with an
+// OpenTelemetry metric.Meter. Instruments keep the xprom/semconv names and labels, which
+// are set as attributes.
+type Meter struct {
+	meter metric.Meter
+}
+
+// New creates a Meter from an OpenTelemetry metric.Meter.
+func New(meter metric.Meter) *Meter {
+	return &Meter{meter: meter}
+}
+
+// Histogram implements xpromkafka.Meter.
+func (m *Meter) Histogram(opts xpromkafka.InstrumentOpts) xpromkafka.Histogram {
+	hopts := []metric.Float64HistogramOption{
+		metric.WithDescription(opts.Help),
+		metric.WithUnit(opts.Unit),
+	}
+
+	if len(opts.Buckets) > 0 {
+		hopts = append(hopts, metric.WithExplicitBucketBoundaries(opts.Buckets...))
+	}
+
+	h, err := m.meter.Float64Histogram(opts.Name, hopts...)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &histogram{h: h, attrs: newAttributes(opts)}
+}
+
+// Counter implements xpromkafka.Meter.
+func (m *Meter) Counter(opts xpromkafka.InstrumentOpts) xpromkafka.Counter {
+	c, err := m.meter.Float64Counter(opts.Name,
+		metric.WithDescription(opts.Help),
+		metric.WithUnit(opts.Unit),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &counter{c: c, attrs: newAttributes(opts)}
+}
+
+// UpDownCounter implements xpromkafka.Meter.
+func (m *Meter) UpDownCounter(opts xpromkafka.InstrumentOpts) xpromkafka.Counter {
+	c, err := m.meter.Float64UpDownCounter(opts.Name,
+		metric.WithDescription(opts.Help),
+		metric.WithUnit(opts.Unit),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &counter{c: c, attrs: newAttributes(opts)}
+}
+
+// ObservableGauge implements xpromkafka.Meter.
+func (m *Meter) ObservableGauge(opts xpromkafka.InstrumentOpts, fn xpromkafka.ObserveFunc) xpromkafka.Observable {
+	attrs := newAttributes(opts)
+
+	g, err := m.meter.Float64ObservableGauge(opts.Name,
+		metric.WithDescription(opts.Help),
+		metric.WithUnit(opts.Unit),
+		metric.WithFloat64Callback(attrs.callback(fn)),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return g
+}
+
+// ObservableCounter implements xpromkafka.Meter.
+func (m *Meter) ObservableCounter(opts xpromkafka.InstrumentOpts, fn xpromkafka.ObserveFunc) xpromkafka.Observable {
+	attrs := newAttributes(opts)
+
+	c, err := m.meter.Float64ObservableCounter(opts.Name,
+		metric.WithDescription(opts.Help),
+		metric.WithUnit(opts.Unit),
+		metric.WithFloat64Callback(attrs.callback(fn)),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return c
+}
+
+type histogram struct {
+	h     metric.Float64Histogram
+	attrs *attributes
+}
+
+func (h *histogram) Record(ctx context.Context, value float64, labels xpromkafka.Labels) {
+	h.h.Record(ctx, value, h.attrs.option(labels))
+}
+
+type float64Adder interface {
+	Add(ctx context.Context, incr float64, options ...metric.AddOption)
+}
+
+type counter struct {
+	c     float64Adder
+	attrs *attributes
+}
+
+func (c *counter) Add(ctx context.Context, value float64, labels xpromkafka.Labels) {
+	c.c.Add(ctx, value, c.attrs.option(labels))
+}
+
+// attributes converts the labels of an instrument to attributes.
+type attributes struct {
+	names []string
+	with  []attribute.KeyValue
+}
+
+func newAttributes(opts xpromkafka.InstrumentOpts) *attributes {
+	a := &attributes{names: opts.Labels}
+
+	for name, value := range opts.ConstLabels {
+		a.with = append(a.with, attribute.String(name, value))
+	}
+
+	return a
+}
+
+func (a *attributes) option(labels xpromkafka.Labels) metric.MeasurementOption {
+	kvs := make([]attribute.KeyValue, 0, len(a.with)+len(a.names))
+	kvs = append(kvs, a.with...)
+
+	for _, name := range a.names {
+		kvs = append(kvs, attribute.String(name, labels[name]))
+	}
+
+	return metric.WithAttributeSet(attribute.NewSet(kvs...))
+}
+
+func (a *attributes) callback(fn xpromkafka.ObserveFunc) metric.Float64Callback {
+	return func(_ context.Context, o metric.Float64Observer) error {
+		fn(observer(func(value float64, labels xpromkafka.Labels) {
+			o.Observe(value, a.option(labels))
+		}))
+
+		return nil
+	}
+}
+
+type observer func(value float64, labels xpromkafka.Labels)
+
+func (f observer) Observe(value float64, labels xpromkafka.Labels) { f(value, labels) }
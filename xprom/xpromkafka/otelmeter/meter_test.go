@@ -0,0 +1,117 @@
+package otelmeter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/xpromkafka"
+)
+
+func TestMeter(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	collector := xpromkafka.NewCollector(
+		xpromkafka.WithMeter(New(provider.Meter("xpromkafka"))),
+		xpromkafka.LatencyBuckets{0.1, 1},
+		xpromkafka.Address("localhost"),
+		xpromkafka.Port(9092),
+		xpromkafka.ErrorClassifer(func(err error) string { return "CustomError" }),
+	)
+
+	handler := collector.ConsumerMiddleware().Middleware(
+		xkafka.HandlerFunc(func(ctx context.Context, m *xkafka.Message) error {
+			err := errors.New("some error")
+			m.AckFail(err)
+
+			return err
+		}),
+	)
+
+	msg := &xkafka.Message{Topic: "test-topic", Group: "test-group", Partition: 12}
+	assert.Error(t, handler.Handle(context.Background(), msg))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := make(map[string]metricdata.Metrics)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	wantAttrs := attribute.NewSet(
+		attribute.String("messaging_system", "kafka"),
+		attribute.String("messaging_operation_name", "consume"),
+		attribute.String("server_address", "localhost"),
+		attribute.String("server_port", "9092"),
+		attribute.String("messaging_consumer_group_name", "test-group"),
+		attribute.String("messaging_destination_name", "test-topic"),
+		attribute.String("messaging_destination_partition_id", "12"),
+		attribute.String("messaging_kafka_message_status", "FAIL"),
+		attribute.String("error_type", "CustomError"),
+	)
+
+	consumed, ok := metrics["messaging_client_consumed_messages"].Data.(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.Len(t, consumed.DataPoints, 1)
+	assert.True(t, consumed.IsMonotonic)
+	assert.Equal(t, 1.0, consumed.DataPoints[0].Value)
+	assert.True(t, wantAttrs.Equals(&consumed.DataPoints[0].Attributes))
+	assert.Equal(t, "{message}", metrics["messaging_client_consumed_messages"].Unit)
+
+	duration, ok := metrics["messaging_client_operation_duration"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, []float64{0.1, 1}, duration.DataPoints[0].Bounds)
+	assert.EqualValues(t, 1, duration.DataPoints[0].Count)
+	assert.Equal(t, "s", metrics["messaging_client_operation_duration"].Unit)
+
+	inflight, ok := metrics["messaging_inflight_messages"].Data.(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.Len(t, inflight.DataPoints, 1)
+	assert.False(t, inflight.IsMonotonic)
+	assert.Zero(t, inflight.DataPoints[0].Value)
+}
+
+func TestMeterObservable(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	stats := xpromkafka.NewStatsCollector(xpromkafka.WithMeter(New(provider.Meter("xpromkafka"))))
+	stats.StatsCallback()(`{"name": "producer-1", "msg_cnt": 3, "txmsgs": 42}`)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := make(map[string]metricdata.Metrics)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	wantAttrs := attribute.NewSet(
+		attribute.String("messaging_system", "kafka"),
+		attribute.String("messaging_client_id", "producer-1"),
+	)
+
+	queue, ok := metrics["messaging_kafka_client_queue_messages"].Data.(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, queue.DataPoints, 1)
+	assert.Equal(t, 3.0, queue.DataPoints[0].Value)
+	assert.True(t, wantAttrs.Equals(&queue.DataPoints[0].Attributes))
+
+	transmitted, ok := metrics["messaging_kafka_client_transmitted_messages"].Data.(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.Len(t, transmitted.DataPoints, 1)
+	assert.True(t, transmitted.IsMonotonic)
+	assert.Equal(t, 42.0, transmitted.DataPoints[0].Value)
+}
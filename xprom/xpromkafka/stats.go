@@ -0,0 +1,270 @@
+package xpromkafka
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gojekfarm/xtools/xkafka"
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+const defaultStatsExpiry = 5 * time.Minute
+
+// StatsCollector exports the librdkafka statistics of xkafka.Consumer,
+// xkafka.BatchConsumer and xkafka.Producer as Prometheus metrics.
+//
+// Statistics are emitted every `statistics.interval.ms`, which must be set
+// with xkafka.ConfigMap. The latest statistics of every client are exported
+// until they expire, so that closed clients stop being reported.
+type StatsCollector struct {
+	expiry time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*clientStats
+
+	collectors []prometheus.Collector
+}
+
+// NewStatsCollector creates a new StatsCollector.
+// Default values:
+// - StatsExpiry: 5m
+func NewStatsCollector(opts ...Option) *StatsCollector {
+	o := options{
+		statsExpiry: defaultStatsExpiry,
+	}
+
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	if o.meter == nil {
+		o.meter = &promMeter{}
+	}
+
+	c := &StatsCollector{
+		expiry:  o.statsExpiry,
+		now:     time.Now,
+		clients: make(map[string]*clientStats),
+	}
+
+	c.collectors = promCollectors(c.newInstruments(o.meter)...)
+
+	return c
+}
+
+func (c *StatsCollector) newInstruments(m Meter) []any {
+	constLabels := Labels{
+		semconv.MessagingSystem: semconv.SystemKafka,
+	}
+
+	clientLabels := []string{semconv.MessagingClientID}
+	brokerLabels := []string{semconv.MessagingClientID, semconv.ServerAddress, semconv.ServerPort}
+	partitionLabels := []string{semconv.MessagingClientID, semconv.MessagingKafkaTopic, semconv.MessagingKafkaPartition}
+
+	gauge := func(name, help, unit string, labels []string, fn ObserveFunc) any {
+		return m.ObservableGauge(InstrumentOpts{
+			Name:        name,
+			Help:        help,
+			Unit:        unit,
+			Labels:      labels,
+			ConstLabels: constLabels,
+		}, fn)
+	}
+
+	counter := func(name, help, unit string, labels []string, fn ObserveFunc) any {
+		return m.ObservableCounter(InstrumentOpts{
+			Name:        name,
+			Help:        help,
+			Unit:        unit,
+			Labels:      labels,
+			ConstLabels: constLabels,
+		}, fn)
+	}
+
+	return []any{
+		gauge(semconv.MessagingKafkaClientQueueMessages, "Messages waiting in the client queues.",
+			UnitMessages, clientLabels, c.observeClients(func(s *clientStats) float64 { return s.MsgCnt })),
+		gauge(semconv.MessagingKafkaClientQueueBytes, "Size of the messages waiting in the client queues.",
+			UnitBytes, clientLabels, c.observeClients(func(s *clientStats) float64 { return s.MsgSize })),
+		counter(semconv.MessagingKafkaClientTransmittedMessages, "Messages transmitted to brokers.",
+			UnitMessages, clientLabels, c.observeClients(func(s *clientStats) float64 { return s.TxMsgs })),
+		counter(semconv.MessagingKafkaClientReceivedMessages, "Messages received from brokers.",
+			UnitMessages, clientLabels, c.observeClients(func(s *clientStats) float64 { return s.RxMsgs })),
+		gauge(semconv.MessagingKafkaBrokerRTT, "Average broker round-trip time in seconds.",
+			UnitSeconds, brokerLabels, c.observeBrokers(func(b brokerStats) float64 {
+				return time.Duration(b.RTT.Avg * float64(time.Microsecond)).Seconds()
+			})),
+		gauge(semconv.MessagingKafkaBrokerOutbufRequests, "Requests waiting to be sent to the broker.",
+			"", brokerLabels, c.observeBrokers(func(b brokerStats) float64 { return b.OutbufCnt })),
+		gauge(semconv.MessagingKafkaBrokerWaitrespRequests, "Requests sent to the broker, waiting for a response.",
+			"", brokerLabels, c.observeBrokers(func(b brokerStats) float64 { return b.WaitrespCnt })),
+		gauge(semconv.MessagingKafkaPartitionConsumerLag,
+			"Difference between the partition high watermark and the committed offset.",
+			UnitMessages, partitionLabels, c.observePartitions(func(p partitionStats) (float64, bool) {
+				// lag is -1 until the offsets are known
+				return p.ConsumerLag, p.ConsumerLag >= 0
+			})),
+		gauge(semconv.MessagingKafkaPartitionQueueMessages, "Messages waiting in the partition producer queue.",
+			UnitMessages, partitionLabels, c.observePartitions(func(p partitionStats) (float64, bool) {
+				return p.MsgqCnt, true
+			})),
+	}
+}
+
+// Register registers the collector with the provided registry.
+func (c *StatsCollector) Register(registry prometheus.Registerer) error {
+	return registry.Register(c)
+}
+
+// StatsCallback returns an xkafka.StatsCallback that records the statistics.
+// It can be shared by multiple consumers and producers.
+// Invalid statistics are ignored.
+func (c *StatsCollector) StatsCallback() xkafka.StatsCallback {
+	return func(stats string) {
+		var s clientStats
+		if err := json.Unmarshal([]byte(stats), &s); err != nil || s.Name == "" {
+			return
+		}
+
+		s.updatedAt = c.now()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.clients[s.Name] = &s
+	}
+}
+
+// Describe implements prometheus.Collector. Instruments of a Meter set
+// with WithMeter are not described, unless they are Prometheus collectors.
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, pc := range c.collectors {
+		pc.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, pc := range c.collectors {
+		pc.Collect(ch)
+	}
+}
+
+func (c *StatsCollector) snapshot() []*clientStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	clients := make([]*clientStats, 0, len(c.clients))
+
+	for name, s := range c.clients {
+		if c.expiry > 0 && now.Sub(s.updatedAt) > c.expiry {
+			delete(c.clients, name)
+
+			continue
+		}
+
+		clients = append(clients, s)
+	}
+
+	return clients
+}
+
+func (c *StatsCollector) observeClients(value func(s *clientStats) float64) ObserveFunc {
+	return func(o Observer) {
+		for _, s := range c.snapshot() {
+			o.Observe(value(s), Labels{semconv.MessagingClientID: s.Name})
+		}
+	}
+}
+
+func (c *StatsCollector) observeBrokers(value func(b brokerStats) float64) ObserveFunc {
+	return func(o Observer) {
+		for _, s := range c.snapshot() {
+			for _, b := range s.Brokers {
+				// bootstrap and coordinator connections duplicate the brokers
+				if b.NodeID < 0 {
+					continue
+				}
+
+				host, port, err := net.SplitHostPort(b.NodeName)
+				if err != nil {
+					host, port = b.NodeName, ""
+				}
+
+				o.Observe(value(b), Labels{
+					semconv.MessagingClientID: s.Name,
+					semconv.ServerAddress:     host,
+					semconv.ServerPort:        port,
+				})
+			}
+		}
+	}
+}
+
+func (c *StatsCollector) observePartitions(value func(p partitionStats) (float64, bool)) ObserveFunc {
+	return func(o Observer) {
+		for _, s := range c.snapshot() {
+			for _, t := range s.Topics {
+				for _, p := range t.Partitions {
+					// the internal unassigned partition
+					if p.Partition < 0 {
+						continue
+					}
+
+					v, ok := value(p)
+					if !ok {
+						continue
+					}
+
+					o.Observe(v, Labels{
+						semconv.MessagingClientID:       s.Name,
+						semconv.MessagingKafkaTopic:     t.Topic,
+						semconv.MessagingKafkaPartition: strconv.Itoa(int(p.Partition)),
+					})
+				}
+			}
+		}
+	}
+}
+
+// clientStats is the subset of the librdkafka statistics that is exported.
+// https://github.com/confluentinc/librdkafka/blob/master/STATISTICS.md
+type clientStats struct {
+	Name    string                 `json:"name"`
+	MsgCnt  float64                `json:"msg_cnt"`
+	MsgSize float64                `json:"msg_size"`
+	TxMsgs  float64                `json:"txmsgs"`
+	RxMsgs  float64                `json:"rxmsgs"`
+	Brokers map[string]brokerStats `json:"brokers"`
+	Topics  map[string]topicStats  `json:"topics"`
+
+	updatedAt time.Time
+}
+
+type brokerStats struct {
+	NodeID      int32   `json:"nodeid"`
+	NodeName    string  `json:"nodename"`
+	OutbufCnt   float64 `json:"outbuf_cnt"`
+	WaitrespCnt float64 `json:"waitresp_cnt"`
+	RTT         struct {
+		Avg float64 `json:"avg"`
+	} `json:"rtt"`
+}
+
+type topicStats struct {
+	Topic      string                    `json:"topic"`
+	Partitions map[string]partitionStats `json:"partitions"`
+}
+
+type partitionStats struct {
+	Partition   int32   `json:"partition"`
+	MsgqCnt     float64 `json:"msgq_cnt"`
+	ConsumerLag float64 `json:"consumer_lag"`
+}
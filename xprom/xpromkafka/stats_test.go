@@ -0,0 +1,99 @@
+package xpromkafka
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStats = `{
+	"name": "rdkafka#consumer-1",
+	"client_id": "rdkafka",
+	"type": "consumer",
+	"msg_cnt": 3,
+	"msg_size": 1024,
+	"txmsgs": 0,
+	"rxmsgs": 42,
+	"brokers": {
+		"localhost:9092/1": {
+			"nodeid": 1,
+			"nodename": "localhost:9092",
+			"outbuf_cnt": 2,
+			"waitresp_cnt": 1,
+			"rtt": {"avg": 1500}
+		},
+		"GroupCoordinator": {
+			"nodeid": -1,
+			"nodename": "localhost:9092"
+		}
+	},
+	"topics": {
+		"test-topic": {
+			"topic": "test-topic",
+			"partitions": {
+				"0": {"partition": 0, "msgq_cnt": 0, "consumer_lag": 7},
+				"1": {"partition": 1, "msgq_cnt": 0, "consumer_lag": -1},
+				"-1": {"partition": -1, "msgq_cnt": 0, "consumer_lag": -1}
+			}
+		}
+	}
+}`
+
+func TestStatsCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewStatsCollector()
+
+	require.NoError(t, collector.Register(reg))
+
+	cb := collector.StatsCallback()
+	cb(testStats)
+	cb("invalid")
+
+	expected := `
+	# HELP messaging_kafka_broker_rtt Average broker round-trip time in seconds.
+	# TYPE messaging_kafka_broker_rtt gauge
+	messaging_kafka_broker_rtt{messaging_client_id="rdkafka#consumer-1",messaging_system="kafka",server_address="localhost",server_port="9092"} 0.0015
+	# HELP messaging_kafka_broker_outbuf_requests Requests waiting to be sent to the broker.
+	# TYPE messaging_kafka_broker_outbuf_requests gauge
+	messaging_kafka_broker_outbuf_requests{messaging_client_id="rdkafka#consumer-1",messaging_system="kafka",server_address="localhost",server_port="9092"} 2
+	# HELP messaging_kafka_client_queue_messages Messages waiting in the client queues.
+	# TYPE messaging_kafka_client_queue_messages gauge
+	messaging_kafka_client_queue_messages{messaging_client_id="rdkafka#consumer-1",messaging_system="kafka"} 3
+	# HELP messaging_kafka_client_received_messages Messages received from brokers.
+	# TYPE messaging_kafka_client_received_messages counter
+	messaging_kafka_client_received_messages{messaging_client_id="rdkafka#consumer-1",messaging_system="kafka"} 42
+	# HELP messaging_kafka_partition_consumer_lag Difference between the partition high watermark and the committed offset.
+	# TYPE messaging_kafka_partition_consumer_lag gauge
+	messaging_kafka_partition_consumer_lag{messaging_client_id="rdkafka#consumer-1",messaging_destination_name="test-topic",messaging_destination_partition_id="0",messaging_system="kafka"} 7
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"messaging_kafka_broker_rtt",
+		"messaging_kafka_broker_outbuf_requests",
+		"messaging_kafka_client_queue_messages",
+		"messaging_kafka_client_received_messages",
+		"messaging_kafka_partition_consumer_lag",
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(collector, "messaging_kafka_partition_queue_messages"))
+}
+
+func TestStatsCollectorExpiry(t *testing.T) {
+	now := time.Now()
+
+	collector := NewStatsCollector(StatsExpiry(time.Minute))
+	collector.now = func() time.Time { return now }
+
+	collector.StatsCallback()(testStats)
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "messaging_kafka_client_queue_messages"))
+
+	now = now.Add(2 * time.Minute)
+
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
@@ -0,0 +1,130 @@
+package xpromtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// AssertCounter asserts that the sum of the counter series that have
+// the labels equals value.
+func AssertCounter(t TestingT, g prometheus.Gatherer, name string, labels Labels, value float64) bool {
+	t.Helper()
+
+	s := Gather(t, g)
+
+	return assertFloat(t, s, name, labels, "counter", dto.MetricType_COUNTER, s.Counter(name, labels), value)
+}
+
+// AssertGauge asserts that the sum of the gauge series that have
+// the labels equals value.
+func AssertGauge(t TestingT, g prometheus.Gatherer, name string, labels Labels, value float64) bool {
+	t.Helper()
+
+	s := Gather(t, g)
+
+	return assertFloat(t, s, name, labels, "gauge", dto.MetricType_GAUGE, s.Gauge(name, labels), value)
+}
+
+// AssertHistogramCount asserts that the histogram series that have
+// the labels have count observations.
+func AssertHistogramCount(t TestingT, g prometheus.Gatherer, name string, labels Labels, count uint64) bool {
+	t.Helper()
+
+	s := Gather(t, g)
+
+	if !assertSeries(t, s, name, labels, dto.MetricType_HISTOGRAM) {
+		return false
+	}
+
+	if got := s.HistogramCount(name, labels); got != count {
+		t.Errorf("xpromtest: histogram %s%s count: expected %d, got %d", name, formatLabels(labels), count, got)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertHistogramSum asserts that the sum of the observations of the
+// histogram series that have the labels equals sum.
+func AssertHistogramSum(t TestingT, g prometheus.Gatherer, name string, labels Labels, sum float64) bool {
+	t.Helper()
+
+	s := Gather(t, g)
+
+	return assertFloat(t, s, name, labels, "histogram sum", dto.MetricType_HISTOGRAM, s.HistogramSum(name, labels), sum)
+}
+
+// AssertNoSeries asserts that no series of the metric have the labels.
+func AssertNoSeries(t TestingT, g prometheus.Gatherer, name string, labels Labels) bool {
+	t.Helper()
+
+	if n := len(Gather(t, g).Series(name, labels)); n > 0 {
+		t.Errorf("xpromtest: %s%s: expected no series, got %d", name, formatLabels(labels), n)
+
+		return false
+	}
+
+	return true
+}
+
+func assertFloat(t TestingT, s Snapshot, name string, labels Labels, kind string, typ dto.MetricType, got, expected float64) bool {
+	t.Helper()
+
+	if !assertSeries(t, s, name, labels, typ) {
+		return false
+	}
+
+	if math.Abs(got-expected) > 1e-9 {
+		t.Errorf("xpromtest: %s %s%s: expected %v, got %v", kind, name, formatLabels(labels), expected, got)
+
+		return false
+	}
+
+	return true
+}
+
+func assertSeries(t TestingT, s Snapshot, name string, labels Labels, typ dto.MetricType) bool {
+	t.Helper()
+
+	mf, ok := s[name]
+	if !ok {
+		t.Errorf("xpromtest: metric %s not found", name)
+
+		return false
+	}
+
+	if got := mf.GetType(); got != typ {
+		t.Errorf("xpromtest: metric %s type mismatch: expected %s, got %s", name, typ, got)
+
+		return false
+	}
+
+	if len(s.Series(name, labels)) == 0 {
+		t.Errorf("xpromtest: %s%s: no series found", name, formatLabels(labels))
+
+		return false
+	}
+
+	return true
+}
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, value))
+	}
+
+	sort.Strings(pairs)
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,13 @@
+// Package xpromtest provides helpers to test the metrics recorded with
+// the xprom packages.
+//
+// NewRegistry creates a throwaway registry for a test, and Gather takes a
+// snapshot of its metric families. The assertions match the series that
+// have the given labels, ignoring the other labels, and sum their values.
+// They fail when the metric is not of the asserted type.
+// Labels that are always set, like `messaging_system` or `server_port`,
+// can be omitted:
+//
+//	xpromtest.AssertCounter(t, reg, semconv.MessagingClientConsumedMessages,
+//		xpromtest.Labels{semconv.MessagingKafkaTopic: "orders"}, 2)
+package xpromtest
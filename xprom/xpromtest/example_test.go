@@ -0,0 +1,38 @@
+package xpromtest_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gojekfarm/xtools/xprom/semconv"
+	"github.com/gojekfarm/xtools/xprom/xpromtest"
+)
+
+type consumerMetrics struct {
+	consumed *prometheus.CounterVec
+}
+
+func (m *consumerMetrics) Register(registry prometheus.Registerer) error {
+	return registry.Register(m.consumed)
+}
+
+func ExampleAssertCounter() {
+	// usually the *testing.T of the test
+	var t *testing.T
+
+	metrics := &consumerMetrics{
+		consumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: semconv.MessagingClientConsumedMessages,
+		}, []string{semconv.MessagingKafkaTopic, semconv.MessagingKafkaPartition}),
+	}
+
+	reg := xpromtest.NewRegistry(t, metrics)
+
+	metrics.consumed.WithLabelValues("orders", "0").Inc()
+	metrics.consumed.WithLabelValues("orders", "1").Inc()
+
+	// matches both partitions
+	xpromtest.AssertCounter(t, reg, semconv.MessagingClientConsumedMessages,
+		xpromtest.Labels{semconv.MessagingKafkaTopic: "orders"}, 2)
+}
@@ -0,0 +1,35 @@
+package xpromtest
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestingT is the subset of testing.TB used by the helpers.
+type TestingT interface {
+	Errorf(format string, args ...any)
+	FailNow()
+	Helper()
+}
+
+// Collector is implemented by the xprom collectors, which register
+// their metrics with a registry.
+type Collector interface {
+	Register(registry prometheus.Registerer) error
+}
+
+// NewRegistry returns a new registry, with the collectors registered.
+// The test fails if a collector can not be registered.
+func NewRegistry(t TestingT, collectors ...Collector) *prometheus.Registry {
+	t.Helper()
+
+	reg := prometheus.NewRegistry()
+
+	for _, c := range collectors {
+		if err := c.Register(reg); err != nil {
+			t.Errorf("xpromtest: register collector: %v", err)
+			t.FailNow()
+		}
+	}
+
+	return reg
+}
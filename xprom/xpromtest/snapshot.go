@@ -0,0 +1,142 @@
+package xpromtest
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Labels are the labels matched by the assertions, by label name.
+// An empty value matches series without the label, or with an empty value.
+type Labels = map[string]string
+
+// Snapshot holds the metric families gathered from a registry, by name.
+type Snapshot map[string]*dto.MetricFamily
+
+// Gather returns a snapshot of the metric families of the gatherer.
+// The test fails if the metrics can not be gathered.
+func Gather(t TestingT, g prometheus.Gatherer) Snapshot {
+	t.Helper()
+
+	families, err := g.Gather()
+	if err != nil {
+		t.Errorf("xpromtest: gather metrics: %v", err)
+		t.FailNow()
+	}
+
+	s := make(Snapshot, len(families))
+
+	for _, mf := range families {
+		s[mf.GetName()] = mf
+	}
+
+	return s
+}
+
+// Series returns the series of the metric that have the labels.
+func (s Snapshot) Series(name string, labels Labels) []*dto.Metric {
+	mf, ok := s[name]
+	if !ok {
+		return nil
+	}
+
+	var series []*dto.Metric
+
+	for _, m := range mf.GetMetric() {
+		if matchLabels(m, labels) {
+			series = append(series, m)
+		}
+	}
+
+	return series
+}
+
+// Counter returns the sum of the counter series that have the labels.
+func (s Snapshot) Counter(name string, labels Labels) float64 {
+	var sum float64
+
+	for _, m := range s.Series(name, labels) {
+		sum += m.GetCounter().GetValue()
+	}
+
+	return sum
+}
+
+// Gauge returns the sum of the gauge series that have the labels.
+func (s Snapshot) Gauge(name string, labels Labels) float64 {
+	var sum float64
+
+	for _, m := range s.Series(name, labels) {
+		sum += m.GetGauge().GetValue()
+	}
+
+	return sum
+}
+
+// HistogramCount returns the number of observations of the histogram
+// series that have the labels.
+func (s Snapshot) HistogramCount(name string, labels Labels) uint64 {
+	var count uint64
+
+	for _, m := range s.Series(name, labels) {
+		count += m.GetHistogram().GetSampleCount()
+	}
+
+	return count
+}
+
+// HistogramSum returns the sum of the observations of the histogram
+// series that have the labels.
+func (s Snapshot) HistogramSum(name string, labels Labels) float64 {
+	var sum float64
+
+	for _, m := range s.Series(name, labels) {
+		sum += m.GetHistogram().GetSampleSum()
+	}
+
+	return sum
+}
+
+// LabelValues returns the sorted, distinct values of a label,
+// across the series of the metric.
+func (s Snapshot) LabelValues(name, label string) []string {
+	seen := make(map[string]struct{})
+
+	for _, m := range s.Series(name, nil) {
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == label {
+				seen[lp.GetValue()] = struct{}{}
+			}
+		}
+	}
+
+	values := make([]string, 0, len(seen))
+	for v := range seen {
+		values = append(values, v)
+	}
+
+	sort.Strings(values)
+
+	return values
+}
+
+func matchLabels(m *dto.Metric, labels Labels) bool {
+	for name, value := range labels {
+		if labelValue(m, name) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return lp.GetValue()
+		}
+	}
+
+	return ""
+}
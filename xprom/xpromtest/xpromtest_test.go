@@ -0,0 +1,188 @@
+package xpromtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojekfarm/xtools/xprom/semconv"
+)
+
+type fakeT struct {
+	errors []string
+	failed bool
+}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+func (f *fakeT) FailNow() { f.failed = true }
+func (f *fakeT) Helper()  {}
+
+type registerFunc func(prometheus.Registerer) error
+
+func (fn registerFunc) Register(r prometheus.Registerer) error { return fn(r) }
+
+func newTestRegistry(t *testing.T) *prometheus.Registry {
+	t.Helper()
+
+	labels := []string{semconv.MessagingKafkaTopic, semconv.MessagingKafkaPartition}
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: semconv.MessagingClientConsumedMessages,
+	}, labels)
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: semconv.MessagingKafkaConsumerLag,
+	}, labels)
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: semconv.MessagingClientOperationDuration,
+	}, labels)
+
+	reg := NewRegistry(t, registerFunc(func(r prometheus.Registerer) error {
+		r.MustRegister(counter, gauge, histogram)
+
+		return nil
+	}))
+
+	counter.WithLabelValues("orders", "0").Add(2)
+	counter.WithLabelValues("orders", "1").Add(3)
+	counter.WithLabelValues("payments", "0").Add(1)
+
+	gauge.WithLabelValues("orders", "0").Set(10)
+	gauge.WithLabelValues("orders", "1").Set(5)
+
+	histogram.WithLabelValues("orders", "0").Observe(0.5)
+	histogram.WithLabelValues("orders", "1").Observe(1.5)
+
+	return reg
+}
+
+func TestNewRegistryError(t *testing.T) {
+	ft := &fakeT{}
+
+	NewRegistry(ft, registerFunc(func(prometheus.Registerer) error {
+		return errors.New("duplicate")
+	}))
+
+	assert.True(t, ft.failed)
+	assert.Equal(t, []string{"xpromtest: register collector: duplicate"}, ft.errors)
+}
+
+func TestSnapshot(t *testing.T) {
+	s := Gather(t, newTestRegistry(t))
+
+	orders := Labels{semconv.MessagingKafkaTopic: "orders"}
+
+	assert.Len(t, s.Series(semconv.MessagingClientConsumedMessages, nil), 3)
+	assert.Len(t, s.Series(semconv.MessagingClientConsumedMessages, orders), 2)
+	assert.Empty(t, s.Series("unknown", nil))
+
+	assert.Equal(t, 6.0, s.Counter(semconv.MessagingClientConsumedMessages, nil))
+	assert.Equal(t, 5.0, s.Counter(semconv.MessagingClientConsumedMessages, orders))
+	assert.Equal(t, 15.0, s.Gauge(semconv.MessagingKafkaConsumerLag, orders))
+	assert.Equal(t, uint64(2), s.HistogramCount(semconv.MessagingClientOperationDuration, orders))
+	assert.Equal(t, 2.0, s.HistogramSum(semconv.MessagingClientOperationDuration, orders))
+
+	assert.Equal(t, []string{"orders", "payments"},
+		s.LabelValues(semconv.MessagingClientConsumedMessages, semconv.MessagingKafkaTopic))
+}
+
+func TestAssertions(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	orders := Labels{semconv.MessagingKafkaTopic: "orders"}
+	partition := Labels{semconv.MessagingKafkaTopic: "payments", semconv.MessagingKafkaPartition: "0"}
+
+	assert.True(t, AssertCounter(t, reg, semconv.MessagingClientConsumedMessages, orders, 5))
+	assert.True(t, AssertCounter(t, reg, semconv.MessagingClientConsumedMessages, partition, 1))
+	assert.True(t, AssertGauge(t, reg, semconv.MessagingKafkaConsumerLag, orders, 15))
+	assert.True(t, AssertHistogramCount(t, reg, semconv.MessagingClientOperationDuration, orders, 2))
+	assert.True(t, AssertHistogramSum(t, reg, semconv.MessagingClientOperationDuration, orders, 2))
+	assert.True(t, AssertNoSeries(t, reg, semconv.MessagingKafkaConsumerLag, partition))
+}
+
+func TestAssertionFailures(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	orders := Labels{semconv.MessagingKafkaTopic: "orders"}
+
+	testcases := []struct {
+		name   string
+		assert func(TestingT) bool
+		err    string
+	}{
+		{
+			name: "counter value",
+			assert: func(ft TestingT) bool {
+				return AssertCounter(ft, reg, semconv.MessagingClientConsumedMessages, orders, 4)
+			},
+			err: `xpromtest: counter messaging_client_consumed_messages` +
+				`{messaging_destination_name="orders"}: expected 4, got 5`,
+		},
+		{
+			name: "gauge missing metric",
+			assert: func(ft TestingT) bool {
+				return AssertGauge(ft, reg, "unknown", nil, 1)
+			},
+			err: "xpromtest: metric unknown not found",
+		},
+		{
+			name: "counter type mismatch",
+			assert: func(ft TestingT) bool {
+				return AssertCounter(ft, reg, semconv.MessagingKafkaConsumerLag, orders, 15)
+			},
+			err: "xpromtest: metric messaging_kafka_consumer_lag type mismatch: expected COUNTER, got GAUGE",
+		},
+		{
+			name: "histogram type mismatch",
+			assert: func(ft TestingT) bool {
+				return AssertHistogramCount(ft, reg, semconv.MessagingClientConsumedMessages, orders, 0)
+			},
+			err: "xpromtest: metric messaging_client_consumed_messages type mismatch: expected HISTOGRAM, got COUNTER",
+		},
+		{
+			name: "histogram count no series",
+			assert: func(ft TestingT) bool {
+				return AssertHistogramCount(ft, reg, semconv.MessagingClientOperationDuration,
+					Labels{semconv.MessagingKafkaTopic: "payments"}, 1)
+			},
+			err: `xpromtest: messaging_client_operation_duration` +
+				`{messaging_destination_name="payments"}: no series found`,
+		},
+		{
+			name: "histogram count value",
+			assert: func(ft TestingT) bool {
+				return AssertHistogramCount(ft, reg, semconv.MessagingClientOperationDuration, orders, 1)
+			},
+			err: `xpromtest: histogram messaging_client_operation_duration` +
+				`{messaging_destination_name="orders"} count: expected 1, got 2`,
+		},
+		{
+			name: "histogram sum value",
+			assert: func(ft TestingT) bool {
+				return AssertHistogramSum(ft, reg, semconv.MessagingClientOperationDuration, nil, 1)
+			},
+			err: "xpromtest: histogram sum messaging_client_operation_duration: expected 1, got 2",
+		},
+		{
+			name: "no series",
+			assert: func(ft TestingT) bool {
+				return AssertNoSeries(ft, reg, semconv.MessagingKafkaConsumerLag, orders)
+			},
+			err: `xpromtest: messaging_kafka_consumer_lag` +
+				`{messaging_destination_name="orders"}: expected no series, got 2`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ft := &fakeT{}
+
+			assert.False(t, tc.assert(ft))
+			assert.Equal(t, []string{tc.err}, ft.errors)
+		})
+	}
+}